@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/shaniidev/keyana/internal/beautify"
+	"github.com/shaniidev/keyana/internal/ui"
+)
+
+// runBeautifyCommand handles `keyana beautify <subcommand>` and returns the exit code
+func runBeautifyCommand(args []string) int {
+	if len(args) == 0 {
+		printBeautifyUsage()
+		return 1
+	}
+
+	switch args[0] {
+	case "file":
+		return runBeautifyFile(args[1:])
+	default:
+		fmt.Printf("Error: unknown beautify subcommand %q\n", args[0])
+		printBeautifyUsage()
+		return 1
+	}
+}
+
+func printBeautifyUsage() {
+	fmt.Println("Usage: keyana beautify <command> [flags]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  file    Print a JavaScript file formatted by the native beautifier")
+}
+
+func runBeautifyFile(args []string) int {
+	fs := flag.NewFlagSet("beautify file", flag.ExitOnError)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Println("Usage: keyana beautify file <file.js>")
+		return 1
+	}
+
+	src, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		ui.Error("%v", err)
+		return 1
+	}
+	out, _, err := beautify.Format(src)
+	if err != nil {
+		ui.Error("%s: %v", fs.Arg(0), err)
+		return 1
+	}
+	os.Stdout.Write(out)
+	return 0
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/shaniidev/keyana/internal/scan"
+	"github.com/shaniidev/keyana/internal/ui"
+)
+
+// runEvidenceCommand handles `keyana evidence <subcommand>` and returns the exit code
+func runEvidenceCommand(args []string) int {
+	if len(args) == 0 || args[0] != "export" {
+		printEvidenceUsage()
+		return 1
+	}
+
+	fs := flag.NewFlagSet("evidence export", flag.ExitOnError)
+	out := fs.String("o", "", "Write the export to this file instead of stdout")
+	format := fs.String("format", "text", "Export format: text or json")
+	fs.Parse(args[1:])
+
+	if fs.NArg() != 1 {
+		printEvidenceUsage()
+		return 1
+	}
+
+	ev, path, err := scan.LoadEvidence(fs.Arg(0))
+	if err != nil {
+		ui.Error("Failed to load evidence: %v", err)
+		return 1
+	}
+	content, err := scan.ExportEvidence(ev, *format)
+	if err != nil {
+		ui.Error("%v", err)
+		return 1
+	}
+
+	if *out == "" {
+		fmt.Print(content)
+		return 0
+	}
+	// The export holds plaintext secrets, like the store it came from
+	if err := os.WriteFile(*out, []byte(content), 0600); err != nil {
+		ui.Error("Failed to write export: %v", err)
+		return 1
+	}
+	ui.Success("Exported %d unredacted findings from %s to %s", len(ev.Secrets), path, *out)
+	return 0
+}
+
+func printEvidenceUsage() {
+	fmt.Println("Usage: keyana evidence export [-o file] [-format text|json] <output dir | evidence file>")
+	fmt.Println()
+	fmt.Println("Prints the unredacted findings of a run. Given an output directory, the")
+	fmt.Println("most recent file in its evidence/ folder is used.")
+}
@@ -2,10 +2,12 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,22 +16,50 @@ import (
 	"github.com/shaniidev/keyana/internal/core"
 	"github.com/shaniidev/keyana/internal/discovery"
 	"github.com/shaniidev/keyana/internal/download"
+	"github.com/shaniidev/keyana/internal/manifest"
 	"github.com/shaniidev/keyana/internal/scan"
 	"github.com/shaniidev/keyana/internal/ui"
 	"github.com/shaniidev/keyana/internal/utils"
+	"github.com/shaniidev/keyana/internal/verify"
+	"github.com/shaniidev/keyana/pkg/keyana"
 )
 
 func main() {
+	// Subcommands bypass the scan pipeline
+	if len(os.Args) > 1 && os.Args[1] == "patterns" {
+		os.Exit(runPatternsCommand(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "evidence" {
+		os.Exit(runEvidenceCommand(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "beautify" {
+		os.Exit(runBeautifyCommand(os.Args[2:]))
+	}
+
 	// Pre-flight check
 	checkDependencies()
 
 	cfg := config.NewConfig()
 	cfg.ParseFlags()
 
+	if _, err := scan.ParseRedactMode(cfg.Redact); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if cfg.Beautifier != beautify.EngineNative && cfg.Beautifier != beautify.EngineExternal {
+		fmt.Printf("Error: unknown beautifier %q (use native or external)\n", cfg.Beautifier)
+		os.Exit(1)
+	}
+
 	if !cfg.Silent {
 		printBanner()
-		// Load secret detection patterns at startup
-		loadTemplatePatterns()
+	}
+
+	// Load secret detection patterns at startup
+	patterns, err := loadTemplatePatterns(cfg)
+	if err != nil {
+		ui.Error("Invalid pattern selection: %v", err)
+		os.Exit(1)
 	}
 
 	if cfg.Domain == "" && cfg.ListFile == "" && cfg.URLsFile == "" && cfg.RawDir == "" && cfg.BeautifiedDir == "" {
@@ -41,6 +71,10 @@ func main() {
 	}
 
 	state := core.NewPipelineState()
+	man, err := manifest.Load(cfg.OutputDir)
+	if err != nil {
+		ui.Warning("Starting a new file manifest, the existing one is unreadable: %v", err)
+	}
 	fmt.Printf("[*] Target: %s\n", cfg.Domain)
 	fmt.Printf("[*] Output: %s\n", cfg.OutputDir)
 
@@ -68,7 +102,13 @@ func main() {
 	// STAGE 2: DOWNLOAD (OR LOAD RAW FILES)
 	// ---------------------------------------------------------
 	if cfg.BeautifiedDir == "" {
-		state.RawJSFiles = runDownloadStage(cfg, state.URLs)
+		state.RawJSFiles = runDownloadStage(cfg, state.URLs, man)
+		if cfg.SourceMaps && len(state.RawJSFiles) > 0 {
+			fmt.Println("\n[STAGE 2b] Source Maps")
+			dl := download.NewDownloader(cfg)
+			dl.Manifest = man
+			state.SourceFiles = dl.FetchSourceMaps(state.RawJSFiles)
+		}
 	} else {
 		fmt.Println("[*] Skipping Download Stage (Beautified Input provided)")
 	}
@@ -80,20 +120,31 @@ func main() {
 	if cfg.BeautifiedDir != "" {
 		scanFiles = loadBeautifiedFiles(cfg)
 	} else {
-		scanFiles = runBeautifyStage(cfg, state.RawJSFiles)
+		scanFiles = runBeautifyStage(cfg, state.RawJSFiles, man)
 	}
 	state.BeautifiedFiles = scanFiles
 
+	// Original sources are readable as is and skip beautification
+	for _, src := range state.SourceFiles {
+		scanFiles = append(scanFiles, src.LocalPath)
+	}
+
 	if len(scanFiles) == 0 {
 		// No files found to scan
 	}
 
+	if err := man.Save(); err != nil {
+		ui.Error("Failed to save file manifest: %v", err)
+	} else if man.Len() > 0 {
+		fmt.Printf("[+] File manifest saved: %s (%d files)\n", man.Path(), man.Len())
+	}
+
 	scanChoice := ui.PromptScanChoice(len(scanFiles))
 
 	// ---------------------------------------------------------
 	// STAGE 4 & 5: SCANNING
 	// ---------------------------------------------------------
-	runScanStage(cfg, state, scanChoice, scanFiles)
+	runScanStage(cfg, state, man, scanChoice, scanFiles, patterns)
 
 	fmt.Println("\n[+] KEYANA Finished. Check output directory.")
 }
@@ -178,7 +229,7 @@ func runDiscoveryStage(cfg *config.Config) []string {
 }
 
 // runDownloadStage handles downloading logic
-func runDownloadStage(cfg *config.Config, urls []string) []*core.JSFile {
+func runDownloadStage(cfg *config.Config, urls []string, man *manifest.Manifest) []*core.JSFile {
 	var rawJSFiles []*core.JSFile
 
 	if cfg.RawDir != "" {
@@ -191,8 +242,10 @@ func runDownloadStage(cfg *config.Config, urls []string) []*core.JSFile {
 
 		for _, f := range files {
 			if !f.IsDir() {
+				localPath := filepath.Join(cfg.RawDir, f.Name())
+				man.Ensure(localPath, "")
 				rawJSFiles = append(rawJSFiles, &core.JSFile{
-					LocalPath:  filepath.Join(cfg.RawDir, f.Name()),
+					LocalPath:  localPath,
 					Filename:   f.Name(),
 					Downloaded: true,
 				})
@@ -265,8 +318,11 @@ func runDownloadStage(cfg *config.Config, urls []string) []*core.JSFile {
 					fmt.Println("[*] Loading existing raw files...")
 					for _, f := range files {
 						if !f.IsDir() && strings.HasSuffix(f.Name(), ".js") {
+							localPath := filepath.Join(rawDir, f.Name())
+							// Keeps the URL recorded by the session that downloaded it
+							man.Ensure(localPath, "")
 							rawJSFiles = append(rawJSFiles, &core.JSFile{
-								LocalPath:  filepath.Join(rawDir, f.Name()),
+								LocalPath:  localPath,
 								Filename:   f.Name(),
 								Downloaded: true,
 							})
@@ -279,7 +335,12 @@ func runDownloadStage(cfg *config.Config, urls []string) []*core.JSFile {
 		if shouldDownload {
 			fmt.Println("\n[STAGE 2] JavaScript Download")
 			dl := download.NewDownloader(cfg)
+			dl.Manifest = man
 			rawJSFiles = dl.Run(urlsToDownload)
+			if cfg.Chunks {
+				chunks := discovery.NewDiscoveryManager(cfg).EnumerateChunks(rawJSFiles, dl)
+				rawJSFiles = append(rawJSFiles, chunks...)
+			}
 		}
 	}
 
@@ -339,7 +400,7 @@ func loadBeautifiedFiles(cfg *config.Config) []string {
 	return scanFiles
 }
 
-func runBeautifyStage(cfg *config.Config, rawJSFiles []*core.JSFile) []string {
+func runBeautifyStage(cfg *config.Config, rawJSFiles []*core.JSFile, man *manifest.Manifest) []string {
 	var scanFiles []string
 	if len(rawJSFiles) > 0 {
 		shouldBeautify := true
@@ -365,6 +426,10 @@ func runBeautifyStage(cfg *config.Config, rawJSFiles []*core.JSFile) []string {
 							scanFiles = append(scanFiles, filepath.Join(beautifiedDir, f.Name()))
 						}
 					}
+					// Beautified copies keep the raw file name
+					for _, f := range rawJSFiles {
+						man.Derive(filepath.Join(beautifiedDir, f.Filename), f.LocalPath)
+					}
 				}
 			}
 		}
@@ -372,6 +437,7 @@ func runBeautifyStage(cfg *config.Config, rawJSFiles []*core.JSFile) []string {
 		if shouldBeautify {
 			fmt.Println("\n[STAGE 3] Beautification")
 			beautifier := beautify.NewBeautifier(cfg)
+			beautifier.Manifest = man
 			beautifiedFiles := beautifier.Run(rawJSFiles)
 			scanFiles = beautifiedFiles
 		}
@@ -388,10 +454,9 @@ func runBeautifyStage(cfg *config.Config, rawJSFiles []*core.JSFile) []string {
 	return scanFiles
 }
 
-func runScanStage(cfg *config.Config, state *core.PipelineState, scanChoice int, scanFiles []string) {
+func runScanStage(cfg *config.Config, state *core.PipelineState, man *manifest.Manifest, scanChoice int, scanFiles []string, patterns []keyana.Pattern) {
 	if scanChoice == 1 || scanChoice == 3 {
 		fmt.Println("\n[STAGE 4] Secret Scanning")
-		ss := scan.NewSecretScanner(cfg)
 
 		// Interactive scan mode selection
 		fmt.Println("\n[?] Select Secret Scan Mode:")
@@ -402,22 +467,82 @@ func runScanStage(cfg *config.Config, state *core.PipelineState, scanChoice int,
 
 		mode := ui.Prompt("Select Mode [1-2]")
 		if mode == "1" {
-			ss.SetSkipGeneric(true)
+			cfg.SkipGeneric = true
 			ui.Info("Running FAST scan (Skipping generic patterns)")
 		} else {
-			ss.SetSkipGeneric(false)
+			cfg.SkipGeneric = false
 			ui.Info("Running DEEP scan (Including generic patterns)")
 		}
 
+		var profiler *keyana.Profiler
+		if cfg.ProfilePatterns {
+			profiler = keyana.NewProfiler(patterns, time.Duration(cfg.PatternBudget)*time.Millisecond)
+			ui.Info("Pattern profiling enabled (budget %dms per file)", cfg.PatternBudget)
+		}
+
+		engine, err := keyana.NewEngine(patterns, keyana.Options{
+			SkipGeneric:   cfg.SkipGeneric,
+			ContextWindow: cfg.ContextWindow,
+			Profiler:      profiler,
+			MinConfidence: cfg.MinConfidence,
+			DecodeDepth:   cfg.DecodeDepth,
+			FoldStrings:   cfg.FoldStrings,
+		})
+		if err != nil {
+			ui.Error("Failed to build the scan engine: %v", err)
+			return
+		}
+		if len(patterns) > 0 {
+			printEngineStats(engine.Stats())
+		}
+
+		ss := scan.NewSecretScanner(cfg, libraryScanner{engine})
+		ss.Ignore = loadIgnoreList(cfg)
+		ss.Manifest = man
 		state.Secrets = ss.Run(scanFiles)
+		state.Suppressed = ss.Suppressed
+		attachOrigins(state)
+		attachRawPositions(state)
 		fmt.Printf("[+] Found %d secrets\n", len(state.Secrets))
+		if state.Suppressed > 0 {
+			fmt.Printf("[*] Suppressed %d findings via %s\n", state.Suppressed, scan.IgnoreFileName)
+		}
+
+		if cfg.Verify {
+			runVerifyStage(cfg, patterns, state.Secrets)
+		}
+
+		// Validated in main
+		redact, _ := scan.ParseRedactMode(cfg.Redact)
+		if path, err := scan.SaveEvidence(state.Secrets, cfg); err != nil {
+			ui.Error("Failed to save evidence: %v", err)
+		} else if redact != scan.RedactNone {
+			fmt.Printf("[+] Unredacted Evidence Saved: %s (export with `keyana evidence export`)\n", path)
+		}
 
-		scan.SaveSecrets(state.Secrets, cfg)
+		report := scan.SecretReport{Secrets: state.Secrets, Suppressed: state.Suppressed, Redact: redact}
+		if cfg.BaselineFile != "" {
+			applyBaseline(cfg, &report)
+		}
+		scan.SaveSecrets(report, cfg)
+
+		if cfg.WriteBaseline != "" {
+			if err := scan.WriteBaseline(cfg.WriteBaseline, state.Secrets, redact); err != nil {
+				ui.Error("Failed to write baseline: %v", err)
+			} else {
+				fmt.Printf("[+] Baseline Saved: %s (%d findings)\n", cfg.WriteBaseline, len(state.Secrets))
+			}
+		}
+		if profiler != nil {
+			printProfileSummary(profiler)
+			scan.SaveProfile(profiler, cfg)
+		}
 	}
 
 	if scanChoice == 2 || scanChoice == 3 {
 		fmt.Println("\n[STAGE 5] Endpoint Extraction")
 		es := scan.NewEndpointScanner(cfg)
+		es.Manifest = man
 		state.Endpoints = es.Run(scanFiles)
 		fmt.Printf("[+] Found %d endpoints\n", len(state.Endpoints))
 
@@ -425,6 +550,41 @@ func runScanStage(cfg *config.Config, state *core.PipelineState, scanChoice int,
 	}
 }
 
+// libraryScanner runs the public engine as the Keyana stage of a scan
+type libraryScanner struct {
+	engine *keyana.Engine
+}
+
+func (s libraryScanner) ScanBytes(ctx context.Context, content []byte, meta core.ScanMeta) ([]core.Secret, error) {
+	found, err := s.engine.ScanBytes(ctx, content, meta)
+	if err != nil {
+		return nil, err
+	}
+	secrets := make([]core.Secret, len(found))
+	for i, f := range found {
+		secrets[i] = core.Secret{
+			Type:       f.Type,
+			Value:      f.Value,
+			Context:    f.Context,
+			File:       f.File,
+			Line:       f.Line,
+			Column:     f.Column,
+			Offset:     f.Offset,
+			EndOffset:  f.EndOffset,
+			Detector:   f.Detector,
+			Decoded:    f.Decoded,
+			PatternID:  f.PatternID,
+			Severity:   f.Severity,
+			Confidence: f.Confidence,
+			Score:      f.Score,
+		}
+		for _, l := range f.Locations {
+			secrets[i].Locations = append(secrets[i].Locations, core.Location{Line: l.Line, Column: l.Column, Offset: l.Offset, EndOffset: l.EndOffset})
+		}
+	}
+	return secrets, nil
+}
+
 func uniqueAPI(in []string) []string {
 	m := make(map[string]bool)
 	var out []string
@@ -450,26 +610,213 @@ func printBanner() {
 	fmt.Println()
 }
 
-func loadTemplatePatterns() {
+// loadTemplatePatterns loads and selects the template patterns. Patterns
+// that fail to load leave only the generic heuristics; a selection matching
+// nothing is an error. Silent mode keeps the warnings and drops the rest.
+func loadTemplatePatterns(cfg *config.Config) ([]keyana.Pattern, error) {
 	start := time.Now()
-	patterns, err := scan.LoadPatterns()
+	patterns, reports, err := keyana.LoadPatterns(cfg.TemplateDirs...)
+	printPatternReports(reports, cfg.Silent)
 	if err != nil {
 		ui.Warning("Failed to load secret detection patterns: %v", err)
 		ui.Warning("Falling back to generic regex scanning only (slower)")
-		return
+		return nil, nil
 	}
 	if len(patterns) == 0 {
 		ui.Warning("No secret detection patterns loaded")
+		return nil, nil
+	}
+	if !cfg.Silent {
+		ui.Success("Loaded %d secret detection patterns in %v", len(patterns), time.Since(start))
+	}
+
+	return selectPatterns(cfg, patterns)
+}
+
+// runVerifyStage checks supported credentials against their provider's API
+func runVerifyStage(cfg *config.Config, patterns []keyana.Pattern, secrets []core.Secret) {
+	baseURLs := make(map[string]string)
+	for _, kv := range cfg.VerifyURLs {
+		provider, url, ok := strings.Cut(kv, "=")
+		if !ok || provider == "" || url == "" {
+			ui.Warning("Ignoring -verify-url %q (expected provider=url)", kv)
+			continue
+		}
+		baseURLs[strings.ToLower(provider)] = url
+	}
+
+	patternTags := make(map[string][]string, len(patterns))
+	for _, p := range patterns {
+		patternTags[p.ID] = p.Tags
+	}
+	registry := verify.NewRegistry(patternTags, verify.DefaultRegistrations(), verify.Options{
+		BaseURLs:    baseURLs,
+		RatePerSec:  cfg.VerifyRate,
+		Timeout:     time.Duration(cfg.Timeout) * time.Second,
+		Concurrency: cfg.Concurrency,
+	})
+
+	fmt.Println("\n[STAGE 4b] Secret Verification")
+	ui.Info("Verifying supported credentials (%.1f requests/s per provider)", cfg.VerifyRate)
+	sum := registry.VerifyAll(context.Background(), secrets)
+	if sum.Checked == 0 {
+		ui.Info("No findings with a supported verifier")
+		return
+	}
+	fmt.Printf("[+] Verified %d credentials: %d live, %d invalid, %d undecided\n", sum.Checked, sum.Live, sum.Invalid, sum.Errors)
+}
+
+// attachOrigins names the original source of findings in files unpacked
+// from a source map. Their URL and hash, like those of every other file,
+// come from the manifest.
+func attachOrigins(state *core.PipelineState) {
+	sources := make(map[string]core.SourceFile, len(state.SourceFiles))
+	for _, src := range state.SourceFiles {
+		sources[src.LocalPath] = src
+	}
+	for i := range state.Secrets {
+		if src, ok := sources[state.Secrets[i].File]; ok {
+			state.Secrets[i].Origin = src.Name
+		}
+	}
+}
+
+// attachRawPositions translates the locations of findings in beautified
+// files to the raw files they were formatted from, using the position maps
+// written by the beautify stage
+func attachRawPositions(state *core.PipelineState) {
+	maps := make(map[string]*beautify.PosMap)
+	for i := range state.Secrets {
+		sec := &state.Secrets[i]
+		pm, seen := maps[sec.File]
+		if !seen {
+			pm, _ = beautify.LoadPosMap(sec.File) // No map: not a beautified file
+			maps[sec.File] = pm
+		}
+		if pm == nil || sec.Line <= 0 {
+			continue
+		}
+
+		sec.RawLine, sec.RawColumn, _ = pm.Raw(sec.Line, max(sec.Column, 1))
+		for j := range sec.Locations {
+			loc := &sec.Locations[j]
+			loc.RawLine, loc.RawColumn, _ = pm.Raw(loc.Line, max(loc.Column, 1))
+		}
+		// -beautified runs only learn the URL from the map
+		if strings.HasPrefix(pm.Source, "http://") || strings.HasPrefix(pm.Source, "https://") {
+			if sec.URL == "" {
+				sec.URL = pm.Source
+			}
+		} else {
+			sec.RawFile = pm.Source
+		}
+	}
+}
+
+// applyBaseline narrows the report to findings missing from the baseline. A
+// baseline that does not exist yet counts as empty so a first run can create
+// it with -write-baseline.
+func applyBaseline(cfg *config.Config, report *scan.SecretReport) {
+	baseline, err := scan.LoadBaseline(cfg.BaselineFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			ui.Error("Failed to load baseline, reporting all findings: %v", err)
+			return
+		}
+		ui.Warning("Baseline %s does not exist yet, every finding is new", cfg.BaselineFile)
+		baseline = scan.NewBaseline(nil)
+	}
+
+	added, resolved := baseline.Diff(report.Secrets)
+	report.Baseline = cfg.BaselineFile
+	report.Unchanged = len(report.Secrets) - len(added)
+	report.Resolved = resolved
+	report.ShowResolved = cfg.ShowResolved
+	report.Secrets = added
+	fmt.Printf("[+] Baseline: %d new, %d unchanged, %d resolved\n", len(added), report.Unchanged, len(resolved))
+}
+
+// loadIgnoreList reads the per-user and per-output-dir ignore files
+func loadIgnoreList(cfg *config.Config) *scan.IgnoreList {
+	ignore, errs := scan.LoadIgnoreFiles(scan.DefaultIgnoreFiles(cfg.OutputDir)...)
+	for _, err := range errs {
+		ui.Warning("Ignore rule skipped: %v", err)
+	}
+	if ignore.Len() > 0 {
+		ui.Info("Loaded %d ignore rules", ignore.Len())
+	}
+	return ignore
+}
+
+// printProfileSummary shows the slowest patterns and those over budget
+func printProfileSummary(profiler *keyana.Profiler) {
+	results := profiler.Results()
+
+	fmt.Println("\n[*] Slowest patterns (total match time):")
+	for i, r := range results {
+		if i == 10 {
+			break
+		}
+		fmt.Printf("    %-45s %10v total  %10v max  %d calls\n",
+			r.ID, r.TotalTime.Round(time.Microsecond), r.MaxTime.Round(time.Microsecond), r.Invocations)
+	}
+
+	var slow []keyana.PatternStats
+	for _, r := range results {
+		if r.OverBudget > 0 {
+			slow = append(slow, r)
+		}
+	}
+	if len(slow) == 0 {
 		return
 	}
-	// Set the patterns so the scanner can use them
-	scan.SetTemplatePatterns(patterns)
-	duration := time.Since(start)
-	ui.Success("Loaded %d secret detection patterns in %v", len(patterns), duration)
+
+	sort.Slice(slow, func(i, j int) bool { return slow[i].OverBudget > slow[j].OverBudget })
+	ui.Warning("%d patterns exceeded the time budget on at least one file", len(slow))
+	for i, r := range slow {
+		if i == 10 {
+			fmt.Printf("    ... %d more in the profile report\n", len(slow)-10)
+			break
+		}
+		fmt.Printf("    %-45s %d files (max %v on %s)\n",
+			r.ID, r.OverBudget, r.MaxTime.Round(time.Millisecond), r.MaxFile)
+	}
+}
+
+// printEngineStats shows how the patterns were spread over the prefilter
+func printEngineStats(stats keyana.Stats) {
+	fmt.Printf("[+] Engine optimized: %d patterns indexed via %d unique keywords (%d case-insensitive), %d fallbacks\n",
+		stats.Indexed, stats.Keywords+stats.FoldKeywords, stats.FoldKeywords, stats.Fallbacks)
+	if stats.Promoted > 0 {
+		fmt.Printf("[+] Keyword sets and case folding moved %d patterns from fallbacks to indexed\n", stats.Promoted)
+	}
+}
+
+// printPatternReports shows per-file results for local pattern files and
+// any embedded file that failed to load cleanly. Quiet keeps only the
+// problems.
+func printPatternReports(reports []keyana.PatternFileReport, quiet bool) {
+	for _, r := range reports {
+		if r.Embedded && !r.HasProblems() {
+			continue
+		}
+		if !r.Embedded && !quiet {
+			ui.Info("Templates %s: %d patterns loaded, %d overrides", r.Path, r.Loaded, len(r.Overrides))
+			for _, id := range r.Overrides {
+				fmt.Printf("  ~ override: %s\n", id)
+			}
+		}
+		for _, c := range r.Conflicts {
+			ui.Warning("%s: duplicate pattern id %s (skipped)", r.Path, c)
+		}
+		for _, e := range r.Errors {
+			ui.Error("%s: %s", r.Path, e)
+		}
+	}
 }
 
 func checkDependencies() {
-	requiredTools := []string{"katana", "gau", "waybackurls", "js-beautify", "gitleaks", "trufflehog", "jsluice", "linkfinder"}
+	requiredTools := []string{"katana", "gau", "waybackurls", "gitleaks", "trufflehog", "jsluice", "linkfinder"}
 	missing := []string{}
 
 	for _, tool := range requiredTools {
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shaniidev/keyana/internal/config"
+	"github.com/shaniidev/keyana/internal/ui"
+	"github.com/shaniidev/keyana/pkg/keyana"
+)
+
+// runPatternsCommand handles `keyana patterns <subcommand>` and returns the exit code
+func runPatternsCommand(args []string) int {
+	if len(args) == 0 {
+		printPatternsUsage()
+		return 1
+	}
+
+	switch args[0] {
+	case "test":
+		return runPatternsTest(args[1:])
+	case "list":
+		return runPatternsList(args[1:])
+	default:
+		fmt.Printf("Error: unknown patterns subcommand %q\n", args[0])
+		printPatternsUsage()
+		return 1
+	}
+}
+
+func printPatternsUsage() {
+	fmt.Println("Usage: keyana patterns <command> [flags]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  list    Show the active patterns with counts per category and provider")
+	fmt.Println("  test    Run the examples.match / no_match blocks of every pattern")
+	fmt.Println()
+	fmt.Println("Every command accepts -templates, -tags, -exclude-tags, -severity, -category and -patterns.")
+}
+
+// selectPatterns applies the pattern selection flags. It returns the input
+// unchanged when no selection flag is set, and an error naming the flags
+// when they select no pattern at all.
+func selectPatterns(cfg *config.Config, patterns []keyana.Pattern) ([]keyana.Pattern, error) {
+	filter := keyana.Filter{
+		Tags:        cfg.PatternTags,
+		ExcludeTags: cfg.ExcludeTags,
+		Severities:  cfg.Severities,
+		Categories:  cfg.Categories,
+		IDs:         cfg.PatternIDs,
+	}
+	if filter.IsEmpty() {
+		return patterns, nil
+	}
+
+	for _, id := range filter.UnknownIDs(patterns) {
+		ui.Warning("Unknown pattern id: %s", id)
+	}
+
+	selected := filter.Apply(patterns)
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no patterns match %s", selectionFlags(cfg))
+	}
+	if !cfg.Silent {
+		ui.Info("Pattern selection: %d of %d patterns active", len(selected), len(patterns))
+	}
+	return selected, nil
+}
+
+// selectionFlags renders the pattern selection flags that are set, e.g.
+// "-severity critical -category cloud"
+func selectionFlags(cfg *config.Config) string {
+	var flags []string
+	for _, f := range []struct {
+		name   string
+		values []string
+	}{
+		{"tags", cfg.PatternTags},
+		{"exclude-tags", cfg.ExcludeTags},
+		{"severity", cfg.Severities},
+		{"category", cfg.Categories},
+		{"patterns", cfg.PatternIDs},
+	} {
+		if len(f.values) > 0 {
+			flags = append(flags, fmt.Sprintf("-%s %s", f.name, strings.Join(f.values, ",")))
+		}
+	}
+	return strings.Join(flags, " ")
+}
+
+// runPatternsList prints the active pattern set grouped by category and provider
+func runPatternsList(args []string) int {
+	fs := flag.NewFlagSet("patterns list", flag.ExitOnError)
+	cfg := config.NewConfig()
+	cfg.RegisterPatternFlags(fs)
+	verbose := fs.Bool("v", false, "List every pattern")
+	fs.Parse(args)
+
+	patterns, reports, err := keyana.LoadPatterns(cfg.TemplateDirs...)
+	printPatternReports(reports, false)
+	if err != nil {
+		ui.Error("Failed to load patterns: %v", err)
+		return 1
+	}
+	patterns, err = selectPatterns(cfg, patterns)
+	if err != nil {
+		ui.Error("Invalid pattern selection: %v", err)
+		return 1
+	}
+
+	type group struct {
+		category string
+		provider string
+	}
+	byCategory := make(map[string]int)
+	byProvider := make(map[group]int)
+	bySeverity := make(map[string]int)
+	for _, p := range patterns {
+		category := orNone(p.Category)
+		byCategory[category]++
+		byProvider[group{category, orNone(p.Provider)}]++
+		bySeverity[strings.ToLower(p.Severity)]++
+	}
+
+	if *verbose {
+		fmt.Printf("\n%s\n", strings.Repeat("-", 100))
+		fmt.Printf("%-45s | %-8s | %-14s | %s\n", "PATTERN", "SEVERITY", "CATEGORY", "TAGS")
+		fmt.Printf("%s\n", strings.Repeat("-", 100))
+		for _, p := range patterns {
+			fmt.Printf("%-45s | %-8s | %-14s | %s\n", p.ID, p.Severity, orNone(p.Category), strings.Join(p.Tags, ","))
+		}
+	}
+
+	categories := make([]string, 0, len(byCategory))
+	for c := range byCategory {
+		categories = append(categories, c)
+	}
+	sort.Strings(categories)
+
+	groups := make([]group, 0, len(byProvider))
+	for g := range byProvider {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].category != groups[j].category {
+			return groups[i].category < groups[j].category
+		}
+		return groups[i].provider < groups[j].provider
+	})
+
+	fmt.Printf("\n%s\n", strings.Repeat("-", 60))
+	fmt.Printf("%-16s | %-30s | %s\n", "CATEGORY", "PROVIDER", "PATTERNS")
+	fmt.Printf("%s\n", strings.Repeat("-", 60))
+	for _, c := range categories {
+		for _, g := range groups {
+			if g.category == c {
+				fmt.Printf("%-16s | %-30s | %d\n", c, g.provider, byProvider[g])
+			}
+		}
+		fmt.Printf("%-16s | %-30s | %d\n", "", "total", byCategory[c])
+	}
+	fmt.Printf("%s\n", strings.Repeat("-", 60))
+
+	var severities []string
+	for _, sev := range []string{"critical", "high", "medium", "low"} {
+		if n := bySeverity[sev]; n > 0 {
+			severities = append(severities, fmt.Sprintf("%s: %d", sev, n))
+		}
+	}
+	if len(severities) == 0 {
+		fmt.Printf("Active patterns: %d\n", len(patterns))
+	} else {
+		fmt.Printf("Active patterns: %d (%s)\n", len(patterns), strings.Join(severities, ", "))
+	}
+	return 0
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// runPatternsTest compiles every pattern and checks it against its examples
+func runPatternsTest(args []string) int {
+	fs := flag.NewFlagSet("patterns test", flag.ExitOnError)
+	cfg := config.NewConfig()
+	cfg.RegisterPatternFlags(fs)
+	verbose := fs.Bool("v", false, "Show passing patterns too")
+	fs.Parse(args)
+
+	patterns, reports, err := keyana.LoadPatterns(cfg.TemplateDirs...)
+	printPatternReports(reports, false)
+	if err != nil {
+		ui.Error("Failed to load patterns: %v", err)
+		return 1
+	}
+	patterns, err = selectPatterns(cfg, patterns)
+	if err != nil {
+		ui.Error("Invalid pattern selection: %v", err)
+		return 1
+	}
+
+	compileErrors := 0
+	for _, r := range reports {
+		compileErrors += len(r.Errors)
+	}
+
+	results := keyana.CheckExamples(patterns)
+
+	fmt.Printf("\n%s\n", strings.Repeat("-", 80))
+	fmt.Printf("%-50s | %-8s | %-8s | %s\n", "PATTERN", "MATCH", "NO_MATCH", "STATUS")
+	fmt.Printf("%s\n", strings.Repeat("-", 80))
+
+	failed := 0
+	for _, r := range results {
+		if r.Passed() && !*verbose {
+			continue
+		}
+
+		status := ui.Green + "PASS" + ui.Reset
+		if !r.Passed() {
+			failed++
+			status = ui.Red + "FAIL" + ui.Reset
+		}
+		fmt.Printf("%-50s | %-8s | %-8s | %s\n",
+			r.PatternID,
+			fmt.Sprintf("%d/%d", r.MatchPassed, r.MatchTotal),
+			fmt.Sprintf("%d/%d", r.NoMatchPass, r.NoMatchTotal),
+			status,
+		)
+		for _, f := range r.Failures {
+			fmt.Printf("    - %s\n", f)
+		}
+	}
+	fmt.Printf("%s\n", strings.Repeat("-", 80))
+
+	withoutExamples := len(patterns) - len(results)
+	fmt.Printf("Patterns with examples: %d (passed: %d, failed: %d)\n", len(results), len(results)-failed, failed)
+	fmt.Printf("Patterns without examples: %d\n", withoutExamples)
+	fmt.Printf("Compile/load errors: %d\n", compileErrors)
+
+	if failed > 0 || compileErrors > 0 {
+		return 1
+	}
+	ui.Success("All pattern examples passed")
+	return 0
+}
@@ -1,23 +1,40 @@
 package beautify
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/shaniidev/keyana/internal/config"
 	"github.com/shaniidev/keyana/internal/core"
+	"github.com/shaniidev/keyana/internal/manifest"
+	"github.com/shaniidev/keyana/internal/sourcemap"
 	"github.com/shaniidev/keyana/internal/ui"
 )
 
+// Beautifier engines
+const (
+	EngineNative   = "native"   // In-process formatter (Format)
+	EngineExternal = "external" // js-beautify
+)
+
+// externalTimeout bounds one js-beautify run
+const externalTimeout = 2 * time.Minute
+
 type Beautifier struct {
-	Config *config.Config
+	Config   *config.Config
+	Manifest *manifest.Manifest // Beautified copies are recorded as derived from the raw files, if set
+
+	hasExternal bool // js-beautify is in PATH
 }
 
 func NewBeautifier(cfg *config.Config) *Beautifier {
-	return &Beautifier{Config: cfg}
+	_, err := exec.LookPath("js-beautify")
+	return &Beautifier{Config: cfg, hasExternal: err == nil}
 }
 
 func (b *Beautifier) Run(files []*core.JSFile) []string {
@@ -42,6 +59,7 @@ func (b *Beautifier) Run(files []*core.JSFile) []string {
 		if info, err := os.Stat(outPath); err == nil && info.Size() > 0 {
 			// Already beautified, add to result and skip
 			skipped++
+			b.Manifest.Derive(outPath, f.LocalPath)
 			beautifiedPaths = append(beautifiedPaths, outPath)
 			f.Beautified = true
 		} else {
@@ -58,6 +76,10 @@ func (b *Beautifier) Run(files []*core.JSFile) []string {
 		return beautifiedPaths
 	}
 
+	if b.Config.Beautifier == EngineExternal && !b.hasExternal {
+		ui.Warning("js-beautify not found in PATH, using the native beautifier")
+	}
+
 	sem := make(chan struct{}, b.Config.Concurrency)
 	var wg sync.WaitGroup
 
@@ -78,11 +100,16 @@ func (b *Beautifier) Run(files []*core.JSFile) []string {
 			// Output path
 			outPath := filepath.Join(beautifiedDir, f.Filename)
 
-			err := b.beautifyFile(inPath, outPath)
+			ms, err := b.beautifyFile(inPath, outPath)
 			if err == nil {
+				mapErr := b.writePosMap(f, outPath, ms)
+				b.Manifest.Derive(outPath, f.LocalPath)
 				mu.Lock()
 				beautifiedPaths = append(beautifiedPaths, outPath)
 				f.Beautified = true
+				if mapErr != nil && logFile != nil {
+					fmt.Fprintf(logFile, "[WARN] %s: no position map: %v\n", f.Filename, mapErr)
+				}
 				mu.Unlock()
 			} else {
 				// Log beautification failure (Rule 10: Log Everything)
@@ -104,8 +131,73 @@ func (b *Beautifier) Run(files []*core.JSFile) []string {
 	return beautifiedPaths
 }
 
-func (b *Beautifier) beautifyFile(inPath, outPath string) error {
+// beautifyFile formats inPath into outPath with the configured engine and
+// returns the position mappings when the engine produces them. The native
+// formatter falls back to js-beautify on input it cannot handle, and
+// js-beautify falls back to the native formatter when it is not installed.
+func (b *Beautifier) beautifyFile(inPath, outPath string) ([]sourcemap.Mapping, error) {
+	if b.Config.Beautifier == EngineExternal && b.hasExternal {
+		return nil, b.external(inPath, outPath)
+	}
+
+	ms, err := b.native(inPath, outPath)
+	if err != nil && b.hasExternal {
+		if extErr := b.external(inPath, outPath); extErr != nil {
+			return nil, fmt.Errorf("native: %v; js-beautify: %v", err, extErr)
+		}
+		return nil, nil
+	}
+	return ms, err
+}
+
+func (b *Beautifier) native(inPath, outPath string) ([]sourcemap.Mapping, error) {
+	src, err := os.ReadFile(inPath)
+	if err != nil {
+		return nil, err
+	}
+	out, ms, err := Format(src)
+	if err != nil {
+		return nil, err
+	}
+	return ms, os.WriteFile(outPath, out, 0644)
+}
+
+func (b *Beautifier) external(inPath, outPath string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), externalTimeout)
+	defer cancel()
+
 	// js-beautify <in> -o <out>
-	cmd := exec.Command("js-beautify", inPath, "-o", outPath)
-	return cmd.Run()
+	cmd := exec.CommandContext(ctx, "js-beautify", inPath, "-o", outPath)
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("js-beautify timed out after %s", externalTimeout)
+		}
+		return err
+	}
+	return nil
+}
+
+// writePosMap saves the position map of a beautified file. Output of
+// js-beautify comes without mappings and is aligned with the raw file here;
+// a partial alignment is not saved, as it would misplace later findings.
+func (b *Beautifier) writePosMap(f *core.JSFile, outPath string, ms []sourcemap.Mapping) error {
+	if ms == nil {
+		raw, err := os.ReadFile(f.LocalPath)
+		if err != nil {
+			return err
+		}
+		out, err := os.ReadFile(outPath)
+		if err != nil {
+			return err
+		}
+		if ms, err = Align(raw, out); err != nil {
+			return err
+		}
+	}
+
+	source := f.URL
+	if source == "" {
+		source = f.LocalPath
+	}
+	return WritePosMap(outPath, source, ms)
 }
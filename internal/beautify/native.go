@@ -0,0 +1,374 @@
+package beautify
+
+import (
+	"bytes"
+
+	"github.com/shaniidev/keyana/internal/jslex"
+	"github.com/shaniidev/keyana/internal/sourcemap"
+)
+
+// indentUnit matches js-beautify's default
+const indentUnit = "    "
+
+// frame is an open bracket
+type frame struct {
+	open     byte   // '{', '(' or '['
+	keyword  string // Keyword before a '(' (for, switch, ...)
+	ternary  int    // Unmatched '?' inside this bracket
+	isSwitch bool   // The body of a switch statement
+	caseOpen bool   // After case/default, before its ':'
+	inCase   bool   // Indented for the statements of a case
+	decl     bool   // In a var/let/const statement
+	inDecl   bool   // Indented for the declarators after the first
+}
+
+// formatter lays out tokens. It only ever changes whitespace between
+// tokens and never removes a line break, so automatic semicolon insertion
+// is unaffected.
+type formatter struct {
+	src    []byte
+	out    bytes.Buffer
+	stack  []frame
+	indent int
+
+	prev        jslex.Token // Last non-comment token
+	prevText    string
+	prevPrefix  bool // prev is a prefix operator (!x, -x, ...x)
+	prevPostfix bool // prev is a postfix ++ or --
+	prevComment bool // A comment was written after prev
+	afterBrace  bool // prev is a '}' that may end a statement
+	switchNext  bool // The next '{' opens a switch body
+	lineOpen    bool // Something was written on the current line
+}
+
+// Format beautifies JavaScript source in process and returns the position
+// mappings from the result back to src. It returns an error if the result
+// does not tokenize back to the same token sequence, which means the input
+// confused the lexer and the output cannot be trusted.
+func Format(src []byte) ([]byte, []sourcemap.Mapping, error) {
+	f := &formatter{src: src, stack: []frame{{open: '{'}}}
+	f.out.Grow(len(src) + len(src)/4)
+
+	lex := jslex.New(src)
+	for tok := lex.Next(); tok.Kind != jslex.EOF; tok = lex.Next() {
+		f.write(tok)
+	}
+	f.newline()
+
+	out := f.out.Bytes()
+	ms, err := Align(src, out)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, ms, nil
+}
+
+// top returns the innermost bracket. The bottom of the stack is the
+// program, which is never popped.
+func (f *formatter) top() *frame {
+	return &f.stack[len(f.stack)-1]
+}
+
+func (f *formatter) pop(open byte) frame {
+	n := len(f.stack)
+	if n == 1 || (f.stack[n-1].open == '{') != (open == '{') {
+		return frame{} // Unbalanced input: keep going
+	}
+	fr := f.stack[n-1]
+	f.stack = f.stack[:n-1]
+	return fr
+}
+
+// dedent undoes the extra indentation of a case or declaration list
+func (f *formatter) dedent(fr *frame) {
+	if fr.inCase {
+		f.indent--
+	}
+	if fr.inDecl {
+		f.indent--
+	}
+	fr.inCase, fr.inDecl = false, false
+}
+
+func (f *formatter) newline() {
+	if !f.lineOpen {
+		return
+	}
+	f.out.WriteByte('\n')
+	f.lineOpen = false
+}
+
+func (f *formatter) emit(s string, spaceBefore bool) {
+	if !f.lineOpen {
+		for i := 0; i < f.indent; i++ {
+			f.out.WriteString(indentUnit)
+		}
+	} else if spaceBefore {
+		f.out.WriteByte(' ')
+	}
+	f.out.WriteString(s)
+	f.lineOpen = true
+}
+
+func (f *formatter) write(tok jslex.Token) {
+	text := string(f.src[tok.Start:tok.End])
+	if tok.NewlineBefore {
+		f.newline()
+		// A declaration list ended by ASI
+		if top := f.top(); top.decl && tok.Kind != jslex.Comment && f.prevText != "," {
+			if top.inDecl {
+				f.indent--
+				top.inDecl = false
+			}
+			top.decl = false
+		}
+	}
+
+	if tok.Kind == jslex.Comment {
+		f.emit(text, true)
+		if text[1] == '/' {
+			f.newline()
+		}
+		f.prevComment = true
+		return
+	}
+
+	if f.afterBrace && statementStart(tok, text) {
+		f.newline()
+	}
+	f.afterBrace = false
+	prefix, postfix := false, false
+
+	switch text {
+	case "{":
+		f.emit("{", f.spaceBefore(tok, text))
+		f.stack = append(f.stack, frame{open: '{', isSwitch: f.switchNext})
+		f.switchNext = false
+		f.indent++
+		f.newline()
+	case "}":
+		if len(f.stack) > 1 && f.top().open == '{' {
+			f.dedent(f.top())
+		}
+		f.pop('{')
+		if f.indent > 0 {
+			f.indent--
+		}
+		if f.prevText == "{" && !f.prevComment {
+			f.trimNewline()
+			f.out.WriteByte('}')
+			f.lineOpen = true
+		} else {
+			f.newline()
+			f.emit("}", false)
+		}
+		f.afterBrace = true
+	case "(", "[":
+		f.emit(text, f.spaceBefore(tok, text))
+		fr := frame{open: text[0]}
+		if text == "(" && f.prev.Kind == jslex.Ident {
+			fr.keyword = f.prevText
+		}
+		f.stack = append(f.stack, fr)
+	case ")", "]":
+		if f.pop(text[0]).keyword == "switch" {
+			f.switchNext = true
+		}
+		f.emit(text, false)
+	case ";":
+		f.emit(";", false)
+		if top := f.top(); top.keyword != "for" {
+			if top.inDecl {
+				f.indent--
+				top.inDecl = false
+			}
+			top.decl = false
+			f.newline()
+		}
+	case ",":
+		f.emit(",", false)
+		if top := f.top(); top.open == '{' {
+			if top.decl && !top.inDecl {
+				f.indent++
+				top.inDecl = true
+			}
+			f.newline()
+		}
+	case "?":
+		f.top().ternary++
+		f.emit("?", true)
+	case ":":
+		top := f.top()
+		switch {
+		case top.ternary > 0:
+			top.ternary--
+			f.emit(":", true)
+		case top.caseOpen:
+			top.caseOpen = false
+			f.emit(":", false)
+			f.indent++
+			top.inCase = true
+			f.newline()
+		default:
+			f.emit(":", false)
+		}
+	default:
+		top := f.top()
+		if tok.Kind == jslex.Ident && f.prevText != "." {
+			switch text {
+			case "case", "default":
+				if top.isSwitch {
+					f.dedent(top)
+					top.caseOpen = true
+					f.newline()
+				}
+			case "var", "let", "const":
+				if top.open == '{' {
+					top.decl = true
+				}
+			}
+		}
+		if tok.Kind == jslex.Punct && isPrefix(text) {
+			// A line break before ++/-- always makes it a prefix (ASI)
+			prefix = f.unaryNext() || text == "#" || text == "@" ||
+				tok.NewlineBefore && (text == "++" || text == "--")
+			postfix = !prefix && (text == "++" || text == "--")
+		}
+		f.emit(text, f.spaceBefore(tok, text))
+	}
+
+	f.prev = tok
+	f.prevText = text
+	f.prevPrefix = prefix
+	f.prevPostfix = postfix
+	f.prevComment = false
+}
+
+// trimNewline joins an empty block onto the line of its '{'
+func (f *formatter) trimNewline() {
+	b := f.out.Bytes()
+	n := len(b)
+	for n > 0 && (b[n-1] == '\n' || b[n-1] == ' ') {
+		n--
+	}
+	f.out.Truncate(n)
+}
+
+// statementStart reports whether tok, following a '}', goes on a new line
+func statementStart(tok jslex.Token, text string) bool {
+	switch tok.Kind {
+	case jslex.Punct:
+		return text == "#" || text == "@" // Private member, decorator
+	case jslex.Ident:
+		switch text {
+		case "else", "catch", "finally", "while", "in", "of", "instanceof":
+			return false
+		}
+	}
+	return true
+}
+
+// keywords that are followed by a space and after which an operator is a
+// prefix one
+var keywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true, "with": true,
+	"return": true, "typeof": true, "void": true, "delete": true, "in": true, "of": true,
+	"instanceof": true, "new": true, "throw": true, "case": true, "else": true, "do": true,
+	"await": true, "yield": true,
+}
+
+// isPrefix reports whether an operator can be a prefix operator
+func isPrefix(p string) bool {
+	switch p {
+	case "!", "~", "+", "-", "++", "--", "...", "#", "@":
+		return true
+	}
+	return false
+}
+
+// unaryNext reports whether an operator after prev is a prefix one
+func (f *formatter) unaryNext() bool {
+	if f.prevPostfix {
+		return false
+	}
+	switch f.prev.Kind {
+	case jslex.EOF:
+		return true
+	case jslex.Punct:
+		switch f.prevText {
+		case ")", "]", "}":
+			return false
+		}
+		return true
+	case jslex.Ident:
+		return keywords[f.prevText]
+	}
+	return false
+}
+
+// spaceBefore decides whether a space separates the previous token from tok
+func (f *formatter) spaceBefore(tok jslex.Token, text string) bool {
+	if f.prev.Kind == jslex.EOF {
+		return false
+	}
+	if f.prev.Kind == jslex.Punct && tok.Kind == jslex.Punct && wouldJoin(f.prevText, text) {
+		return true // a + +b, a - --b
+	}
+	if f.prevPrefix {
+		return false
+	}
+	switch f.prevText {
+	case "(", "[", ".", "?.":
+		return false
+	}
+
+	switch text {
+	case "(", "[":
+		switch f.prev.Kind {
+		case jslex.Ident:
+			return keywords[f.prevText]
+		case jslex.Punct:
+			switch f.prevText {
+			case ")", "]", "}":
+				return false
+			}
+			return true
+		}
+		return false
+	case "++", "--":
+		return f.unaryNext() // No space before a postfix operator
+	}
+	if tok.Kind == jslex.Template && f.prev.Kind == jslex.Ident && !keywords[f.prevText] {
+		return false // Tagged template
+	}
+
+	if tok.Kind == jslex.Punct && text != "#" && text != "@" {
+		return text == "{" || isOperator(text)
+	}
+	return true
+}
+
+// wouldJoin reports whether two punctuators written together would read
+// as a different token
+func wouldJoin(a, b string) bool {
+	last, first := a[len(a)-1], b[0]
+	switch last {
+	case '+', '-':
+		return first == last
+	case '/':
+		return first == '/' || first == '*'
+	}
+	return false
+}
+
+// isOperator reports whether a punctuator is spaced like a binary operator
+func isOperator(p string) bool {
+	switch p {
+	case "=", "==", "===", "!=", "!==", "<", ">", "<=", ">=", "+", "-", "*", "/", "%", "**",
+		"&", "|", "^", "&&", "||", "??", "<<", ">>", ">>>", "=>", "+=", "-=", "*=", "/=",
+		"%=", "**=", "<<=", ">>=", ">>>=", "&=", "|=", "^=", "&&=", "||=", "??=", "!", "~",
+		"++", "--", "...":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package beautify
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden files with the current output")
+
+// TestGolden formats each testdata/<name>.min.js and compares the result with
+// testdata/<name>.golden.js
+func TestGolden(t *testing.T) {
+	fixtures, _ := filepath.Glob(filepath.Join("testdata", "*.min.js"))
+	if len(fixtures) == 0 {
+		t.Fatal("no *.min.js fixtures in testdata")
+	}
+
+	for _, path := range fixtures {
+		name := strings.TrimSuffix(filepath.Base(path), ".min.js")
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _, err := Format(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			goldenPath := strings.TrimSuffix(path, ".min.js") + ".golden.js"
+			if *update {
+				if err := os.WriteFile(goldenPath, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if line, ok := firstDiff(got, want); !ok {
+				t.Errorf("output differs from %s at line %d:\n%s", filepath.Base(goldenPath), line, got)
+			}
+		})
+	}
+}
+
+// firstDiff returns the first line (1-based) where got and want differ
+func firstDiff(got, want []byte) (int, bool) {
+	if bytes.Equal(got, want) {
+		return 0, true
+	}
+	g, w := bytes.Split(got, []byte("\n")), bytes.Split(want, []byte("\n"))
+	for i := range g {
+		if i >= len(w) || !bytes.Equal(g[i], w[i]) {
+			return i + 1, false
+		}
+	}
+	return len(g) + 1, false
+}
@@ -0,0 +1,139 @@
+package beautify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/shaniidev/keyana/internal/jslex"
+	"github.com/shaniidev/keyana/internal/sourcemap"
+)
+
+// MapsDir is where position maps are kept, inside the beautified directory
+const MapsDir = "maps"
+
+// PosMap maps positions in a beautified file back to the raw file
+type PosMap struct {
+	Source   string // URL of the raw file, or its path when it has none
+	mappings []sourcemap.Mapping
+}
+
+// cursor converts increasing byte offsets of a buffer to line and column
+type cursor struct {
+	src             []byte
+	off, line, base int // base is the offset of the current line
+}
+
+func (c *cursor) at(off int) (int, int) {
+	for ; c.off < off; c.off++ {
+		if c.src[c.off] == '\n' {
+			c.line++
+			c.base = c.off + 1
+		}
+	}
+	return c.line, off - c.base
+}
+
+// Align pairs the tokens of a raw file with those of its beautified copy
+// and returns a mapping for the start of every token, and every line inside
+// a multi-line token, of the copy. Comments may be re-indented by the
+// formatter; any other difference stops the alignment and is returned as
+// an error together with the mappings made so far.
+func Align(raw, beautified []byte) ([]sourcemap.Mapping, error) {
+	a, b := jslex.New(raw), jslex.New(beautified)
+	ca, cb := &cursor{src: raw}, &cursor{src: beautified}
+	var ms []sourcemap.Mapping
+
+	add := func(rawOff, outOff int) {
+		var m sourcemap.Mapping
+		m.GenLine, m.GenCol = cb.at(outOff)
+		m.SrcLine, m.SrcCol = ca.at(rawOff)
+		ms = append(ms, m)
+	}
+
+	for n := 0; ; n++ {
+		ta, tb := a.Next(), b.Next()
+		if ta.Kind == jslex.EOF && tb.Kind == jslex.EOF {
+			return ms, nil
+		}
+		textA, textB := a.Text(ta), b.Text(tb)
+		if ta.Kind == jslex.Comment && tb.Kind == jslex.Comment {
+			add(ta.Start, tb.Start)
+			continue
+		}
+		if ta.Kind != tb.Kind || !bytes.Equal(textA, textB) {
+			return ms, fmt.Errorf("token %d changed at offset %d: %q became %q", n, ta.Start, textA, textB)
+		}
+
+		add(ta.Start, tb.Start)
+		for k := bytes.IndexByte(textA, '\n'); k >= 0; {
+			add(ta.Start+k+1, tb.Start+k+1)
+			next := bytes.IndexByte(textA[k+1:], '\n')
+			if next < 0 {
+				break
+			}
+			k += next + 1
+		}
+	}
+}
+
+// MapPath returns where the position map of a beautified file is stored
+func MapPath(beautifiedPath string) string {
+	return filepath.Join(filepath.Dir(beautifiedPath), MapsDir, filepath.Base(beautifiedPath)+".map")
+}
+
+// WritePosMap saves ms as a source map next to the beautified file. The
+// raw file is its only source. Columns are byte offsets.
+func WritePosMap(beautifiedPath, source string, ms []sourcemap.Mapping) error {
+	path := MapPath(beautifiedPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(sourcemap.Map{
+		Version:  3,
+		File:     filepath.Base(beautifiedPath),
+		Sources:  []string{source},
+		Names:    []string{},
+		Mappings: sourcemap.EncodeMappings(ms),
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadPosMap reads the position map of a beautified file
+func LoadPosMap(beautifiedPath string) (*PosMap, error) {
+	data, err := os.ReadFile(MapPath(beautifiedPath))
+	if err != nil {
+		return nil, err
+	}
+	m, err := sourcemap.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Sources) != 1 {
+		return nil, fmt.Errorf("position map has %d sources, want 1", len(m.Sources))
+	}
+	ms, err := sourcemap.DecodeMappings(m.Mappings)
+	if err != nil {
+		return nil, err
+	}
+	return &PosMap{Source: m.Sources[0], mappings: ms}, nil
+}
+
+// Raw returns the raw position of a 1-based line and byte column of the
+// beautified file
+func (p *PosMap) Raw(line, col int) (int, int, bool) {
+	m, ok := sourcemap.Lookup(p.mappings, line-1, col-1)
+	if !ok {
+		return 0, 0, false
+	}
+	if m.GenLine != line-1 {
+		// Before the first token of the line: the nearest token will do
+		return m.SrcLine + 1, m.SrcCol + 1, true
+	}
+	return m.SrcLine + 1, m.SrcCol + col - m.GenCol, true
+}
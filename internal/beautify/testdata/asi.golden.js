@@ -0,0 +1,13 @@
+let a = 1
+let b = a
+++b
+const c = function() {
+    return 1
+}
+(function() {})()
+x = y
+/ re / g.test(z)
+function f() {
+    return
+    1
+}
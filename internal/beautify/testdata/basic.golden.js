@@ -0,0 +1,28 @@
+!function() {
+    "use strict";
+    var e = 1,
+        t = "a;b{c}",
+        n = {
+            a: 1,
+            b: [1, 2, 3],
+            c: function(e) {
+                return e + 1
+            }
+        };
+    function r(e, t) {
+        if (e > t) {
+            return e
+        } else if (e < t) return t;
+        else {
+            for (var n = 0; n < e; n++) t += n;
+            return t
+        }
+    }
+    try {
+        r(1, 2)
+    } catch (e) {
+        console.log(e)
+    } finally {
+        n = null
+    }
+}();
@@ -0,0 +1 @@
+var a=/[/}{;]+/g.test(x),b=x/2/y,c=(d)/e,f=[/a/,/b\//],g=typeof/x/;if(a)/re/.exec(s);var h=x.replace(/"/g,"'").split(/\s+/);
@@ -0,0 +1 @@
+switch(e.type){case"a":return x?{default:1}:y;case 2:case 3:break;default:z=e.default}var q=a?b:c?d:e,w=a??b,o=p?.q?.[0]?.(1),s=[...a,...b],v=!a&&-b|~c,i=a++ + ++b-(-c);
@@ -0,0 +1,5 @@
+const u = `${base}/api/${v}?q=${encodeURIComponent(`${a}{}`)}`,
+    k = tag`x${1}y`;
+let o = {
+    [`k${i}`]: `}{;`
+};
@@ -0,0 +1,20 @@
+(self.webpackChunkapp = self.webpackChunkapp || []).push([[179], {
+    42: (e, t, n) => {
+        "use strict";
+        n.d(t, {
+            Z: () => o
+        });
+        var r = n(7294);
+        class o extends r.Component {
+            #k = "AKIA";
+            static x = 1;
+            async load() {
+                const e = await fetch(`/api/${this.#k}`);
+                return e.ok ? e.json() : null
+            }
+            render() {
+                return r.createElement("div", null, this.props.children)
+            }
+        } /* keep */ // line
+    }
+}]);
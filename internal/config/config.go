@@ -18,16 +18,85 @@ type Config struct {
 	URLsFile      string
 	RawDir        string
 	BeautifiedDir string
+	TemplateDirs  StringList // Extra pattern directories merged with the embedded set
+	ContextWindow int        // Bytes around a match searched for context keywords
+
+	// Pattern selection (empty = all patterns)
+	PatternTags StringList
+	ExcludeTags StringList
+	Severities  StringList
+	Categories  StringList
+	PatternIDs  StringList
+
+	MinConfidence   int        // Drop findings scoring below this (0-100)
+	BaselineFile    string     // Previous findings export; only new findings are reported
+	ShowResolved    bool       // List baseline findings that disappeared
+	WriteBaseline   string     // Save this run's findings as a baseline
+	Verify          bool       // Check supported credentials against their provider's API
+	VerifyURLs      StringList // provider=url overrides of verifier API roots
+	VerifyRate      float64    // Verification requests per second per provider
+	ProfilePatterns bool       // Record per-pattern timing and write a profile report
+	PatternBudget   int        // Milliseconds a pattern may spend on one file before it is flagged
+	Redact          string     // Secret display in reports: partial, hash or none (plaintext)
+	DecodeDepth     int        // Encoding layers decoded off string literals (0 disables)
+	FoldStrings     bool       // Scan constant string concatenations as one string
+	SourceMaps      bool       // Fetch bundles' source maps and scan the original sources
+	Chunks          bool       // Download lazily loaded webpack/Vite chunks referenced by bundles
+	Beautifier      string     // Beautify engine: native or external (js-beautify)
+
+	// External secret scanners to run after the Keyana stage
+	Gitleaks   bool
+	TruffleHog bool
+	JSLuice    bool
+}
+
+// StringList is a repeatable string flag that also accepts comma-separated values
+type StringList []string
+
+func (l *StringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *StringList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
 }
 
 func NewConfig() *Config {
 	return &Config{
-		Concurrency: 20,
-		Timeout:     10,
-		OutputDir:   "keyana_output",
+		Concurrency:   20,
+		Timeout:       10,
+		OutputDir:     "keyana_output",
+		ContextWindow: 100,
+		PatternBudget: 50,
+		VerifyRate:    1,
+		DecodeDepth:   2,
+		FoldStrings:   true,
+		SourceMaps:    true,
+		Chunks:        true,
+		Beautifier:    "native",
+		Gitleaks:      true,
+		TruffleHog:    true,
+		JSLuice:       true,
+		Redact:        "partial",
 	}
 }
 
+// RegisterPatternFlags adds the pattern loading and selection flags to fs.
+// Subcommands share them with the main scan.
+func (c *Config) RegisterPatternFlags(fs *flag.FlagSet) {
+	fs.Var(&c.TemplateDirs, "templates", "Directory of extra pattern YAML files (repeatable)")
+	fs.Var(&c.PatternTags, "tags", "Only use patterns with any of these tags (comma-separated)")
+	fs.Var(&c.ExcludeTags, "exclude-tags", "Skip patterns with any of these tags (comma-separated)")
+	fs.Var(&c.Severities, "severity", "Only use patterns of these severities (e.g. high,critical)")
+	fs.Var(&c.Categories, "category", "Only use patterns from these categories (e.g. cloud,payment)")
+	fs.Var(&c.PatternIDs, "patterns", "Only use these pattern IDs (comma-separated)")
+}
+
 func (c *Config) ParseFlags() {
 	flag.StringVar(&c.Domain, "d", "", "Target domain or URL")
 	flag.StringVar(&c.ListFile, "l", "", "List of domains (file)")
@@ -41,6 +110,28 @@ func (c *Config) ParseFlags() {
 	flag.StringVar(&c.URLsFile, "urls", "", "File containing URLs to download (Skips Discovery)")
 	flag.StringVar(&c.RawDir, "raw", "", "Directory containing raw JS files (Skips Discovery & Download)")
 	flag.StringVar(&c.BeautifiedDir, "beautified", "", "Directory containing beautified JS files (Skips all previous stages, goes to Scan)")
+	flag.BoolVar(&c.Chunks, "chunks", true, "Download lazily loaded webpack/Vite chunks referenced by downloaded bundles")
+	flag.BoolVar(&c.SourceMaps, "sourcemaps", true, "Fetch source maps of downloaded bundles and scan the original sources")
+	flag.StringVar(&c.Beautifier, "beautifier", "native", "Beautify engine: native (built in) or external (js-beautify); each falls back to the other")
+
+	// Pattern Flags
+	c.RegisterPatternFlags(flag.CommandLine)
+	flag.IntVar(&c.ContextWindow, "context-window", 100, "Bytes around a match searched for pattern context keywords (0 disables)")
+	flag.IntVar(&c.MinConfidence, "min-confidence", 0, "Drop findings whose confidence score (0-100) is below this")
+	flag.StringVar(&c.BaselineFile, "baseline", "", "Baseline file from a previous run; report only new findings")
+	flag.BoolVar(&c.ShowResolved, "resolved", false, "With -baseline, also list baseline findings that disappeared")
+	flag.StringVar(&c.WriteBaseline, "write-baseline", "", "Write this run's findings to a baseline file")
+	flag.BoolVar(&c.Verify, "verify", false, "Verify supported credentials with a read-only call to the provider's API")
+	flag.Var(&c.VerifyURLs, "verify-url", "Override a verifier's API root as provider=url (repeatable)")
+	flag.Float64Var(&c.VerifyRate, "verify-rate", 1, "Verification requests per second per provider")
+	flag.BoolVar(&c.ProfilePatterns, "profile-patterns", false, "Profile per-pattern match time and write reports/pattern_profile.txt")
+	flag.IntVar(&c.PatternBudget, "pattern-budget", 50, "Time budget in ms for one pattern on one file; slower runs are flagged when profiling")
+	flag.IntVar(&c.DecodeDepth, "decode-depth", 2, "Encoding layers (base64, hex, escapes, URL) decoded off string literals before scanning (0 disables)")
+	flag.BoolVar(&c.FoldStrings, "fold-strings", true, "Scan constant string concatenations (\"AK\" + \"IA...\") as one string")
+	flag.BoolVar(&c.Gitleaks, "gitleaks", true, "Run Gitleaks on the scanned files when it is installed")
+	flag.BoolVar(&c.TruffleHog, "trufflehog", true, "Run TruffleHog on the scanned files when it is installed")
+	flag.BoolVar(&c.JSLuice, "jsluice", true, "Run JSLuice on the scanned files when it is installed")
+	flag.StringVar(&c.Redact, "redact", "partial", "Redact secrets in reports and baselines: partial (first/last 4 chars), hash or none; full values go to evidence/")
 
 	flag.Parse()
 
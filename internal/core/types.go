@@ -9,15 +9,74 @@ type JSFile struct {
 	LocalPath  string
 	Downloaded bool
 	Beautified bool
+
+	SourceMapURL string // Map the bundle's original sources came from ("" = none found)
+}
+
+// SourceFile is an original source unpacked from a bundle's source map
+type SourceFile struct {
+	LocalPath string
+	Name      string // Source name in the map, e.g. webpack:///./src/api.js
+	MapURL    string
+	BundleURL string
 }
 
 // Secret represents a found secret
 type Secret struct {
-	Type     string
-	Value    string
-	File     string
-	Line     int
-	Detector string // e.g. "gitleaks", "regex"
+	Type      string
+	Value     string // The credential itself
+	Context   string // Full matched text surrounding the credential (may equal Value)
+	File      string
+	URL       string // URL File was downloaded from, when known
+	SHA256    string // Hash of the content as downloaded from URL
+	Origin    string // Original source name when File was unpacked from a source map
+	Line      int
+	Column    int // 1-based byte column of the first occurrence
+	Offset    int // Byte offset of the first occurrence
+	EndOffset int
+	Locations []Location // Every occurrence of Value in File, in order
+	RawLine   int        // Position of the first occurrence in the file as served,
+	RawColumn int        // when File is a beautified copy (0 = unknown)
+	RawFile   string     // Raw file File was beautified from, when it has no URL
+	Detector  string     // e.g. "gitleaks", "Template"
+	Detectors []string   // Every detector that reported the finding, when merged
+	Decoded   string     // How the value was hidden, outermost first (e.g. "base64", "concat")
+
+	PatternID  string // Template pattern that produced the finding
+	Severity   string // critical, high, medium or low ("" if the detector has none)
+	Confidence int    // Base confidence of the pattern or detector (0-100)
+	Score      int    // Confidence adjusted for entropy, context and file (0-100)
+
+	Verifier          string // Provider that checked the credential ("" = not checked)
+	Verified          bool   // The provider accepted the credential
+	VerificationError string // Why the check could not decide
+}
+
+// Location is a single occurrence of a secret within a file
+type Location struct {
+	Line      int
+	Column    int
+	Offset    int
+	EndOffset int
+	RawLine   int // Position in the raw file when File is beautified (0 = unknown)
+	RawColumn int
+}
+
+// AddLocation records an occurrence; the first one also sets the
+// Line/Column/Offset fields of the secret
+func (s *Secret) AddLocation(loc Location) {
+	if len(s.Locations) == 0 {
+		s.Line = loc.Line
+		s.Column = loc.Column
+		s.Offset = loc.Offset
+		s.EndOffset = loc.EndOffset
+	}
+	s.Locations = append(s.Locations, loc)
+}
+
+// ScanMeta describes the content handed to a scanner
+type ScanMeta struct {
+	Path string // Reported as Secret.File
 }
 
 // Endpoint represents a found endpoint
@@ -25,6 +84,8 @@ type Endpoint struct {
 	Path   string
 	Method string
 	File   string
+	URL    string // URL File was downloaded from, when known
+	SHA256 string // Hash of the content as downloaded from URL
 	Source string // e.g. "linkfinder"
 }
 
@@ -33,7 +94,9 @@ type PipelineState struct {
 	URLs            []string
 	RawJSFiles      []*JSFile
 	BeautifiedFiles []string
+	SourceFiles     []SourceFile
 	Secrets         []Secret
+	Suppressed      int // Secrets dropped by ignore rules
 	Endpoints       []Endpoint
 
 	// Locks for concurrent access
@@ -0,0 +1,423 @@
+package discovery
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/shaniidev/keyana/internal/core"
+	"github.com/shaniidev/keyana/internal/download"
+	"github.com/shaniidev/keyana/internal/jslex"
+	"github.com/shaniidev/keyana/internal/ui"
+)
+
+// maxChunkRounds bounds the download/enumerate loop in case chunk names
+// keep changing (e.g. cache-busting query strings)
+const maxChunkRounds = 10
+
+var (
+	// __webpack_require__.p = "/static/" (minified: r.p="/static/")
+	publicPathRe = regexp.MustCompile(`[\w$]\.p\s*=\s*["']([^"']*)["']`)
+	// __webpack_require__.e(123) / n.e("src_App_js")
+	ensureChunkRe = regexp.MustCompile(`[\w$]\.e\(\s*(\d+|"[^"]+"|'[^']+')\s*\)`)
+	// import("./About-1a2b3c.js")
+	dynamicImportRe = regexp.MustCompile("import\\(\\s*[\"'`]([^\"'`]+\\.m?js)[\"'`]\\s*\\)")
+)
+
+// EnumerateChunks downloads the lazily loaded chunks referenced by the
+// webpack runtime or Vite preload map of each bundle, then the chunks those
+// reference, until a round turns up nothing new. It returns the new files.
+func (dm *DiscoveryManager) EnumerateChunks(files []*core.JSFile, dl *download.Downloader) []*core.JSFile {
+	seen := make(map[string]bool)
+	for _, f := range files {
+		seen[f.URL] = true
+	}
+
+	listPath := filepath.Join(dm.Config.OutputDir, "urls", "chunk_urls.txt")
+	list, _ := os.OpenFile(listPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	defer list.Close()
+
+	var found []*core.JSFile
+	frontier := files
+	for round := 1; round <= maxChunkRounds; round++ {
+		var next []string
+		for _, f := range frontier {
+			if !f.Downloaded || f.URL == "" {
+				continue
+			}
+			content, err := os.ReadFile(f.LocalPath)
+			if err != nil {
+				continue
+			}
+			for _, u := range ChunkURLs(content, f.URL) {
+				if !seen[u] {
+					seen[u] = true
+					next = append(next, u)
+				}
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+
+		sort.Strings(next)
+		for _, u := range next {
+			fmt.Fprintln(list, u)
+		}
+		ui.Info("Chunk round %d: %d new lazily loaded chunks", round, len(next))
+		frontier = dl.Run(next)
+		found = append(found, frontier...)
+	}
+	return found
+}
+
+// ChunkURLs returns the chunk URLs a bundle can load lazily, resolved
+// against the bundle's URL. It understands the webpack 4/5 chunk filename
+// function (__webpack_require__.u or jsonpScriptSrc), Vite's
+// __vite__mapDeps list and literal dynamic imports.
+func ChunkURLs(content []byte, bundleURL string) []string {
+	var paths []string
+	publicPath := "auto"
+	if m := publicPathRe.FindSubmatch(content); m != nil {
+		publicPath = string(m[1])
+	}
+
+	for _, fn := range chunkFilenameFuncs(content) {
+		ids := fn.ids
+		if len(ids) == 0 {
+			ids = ensuredChunkIDs(content)
+		}
+		for _, id := range ids {
+			if p, ok := fn.eval(id); ok {
+				paths = append(paths, resolveChunk(bundleURL, publicPath, p))
+			}
+		}
+	}
+
+	for _, p := range viteDeps(content) {
+		paths = append(paths, resolveChunk(bundleURL, "auto", p))
+	}
+	for _, m := range dynamicImportRe.FindAllSubmatch(content, -1) {
+		if u, err := resolveURL(bundleURL, string(m[1])); err == nil {
+			paths = append(paths, u)
+		}
+	}
+
+	// Deduplicate, keeping order
+	seen := make(map[string]bool)
+	out := paths[:0]
+	for _, p := range paths {
+		if p != "" && p != bundleURL && !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// ensuredChunkIDs returns the IDs passed to __webpack_require__.e
+func ensuredChunkIDs(content []byte) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, m := range ensureChunkRe.FindAllSubmatch(content, -1) {
+		id := strings.Trim(string(m[1]), `"'`)
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// chunkPart is one operand of a chunk filename expression
+type chunkPart struct {
+	literal    string
+	lookup     map[string]string // {id: value}[id]
+	idFallback bool              // ({...}[id] || id): missing keys use the id
+	isID       bool
+	publicPath bool // __webpack_require__.p, added by resolveChunk
+}
+
+// chunkFunc is a parsed chunk filename function
+type chunkFunc struct {
+	parts []chunkPart
+	ids   []string // Chunk IDs listed in its lookup tables
+}
+
+func (f chunkFunc) eval(id string) (string, bool) {
+	var sb strings.Builder
+	for _, p := range f.parts {
+		switch {
+		case p.isID:
+			sb.WriteString(id)
+		case p.lookup != nil:
+			v, ok := p.lookup[id]
+			if !ok {
+				if !p.idFallback {
+					return "", false
+				}
+				v = id
+			}
+			sb.WriteString(v)
+		case p.publicPath:
+		default:
+			sb.WriteString(p.literal)
+		}
+	}
+	return sb.String(), true
+}
+
+// chunkFilenameFuncs finds functions of one parameter returning a string
+// concatenation that includes a {id: hash}[id] table, or that are assigned
+// to __webpack_require__.u
+func chunkFilenameFuncs(content []byte) []chunkFunc {
+	if !bytes.Contains(content, []byte("[")) {
+		return nil
+	}
+	lex := jslex.New(content)
+	var toks []jslex.Token
+	for tok := lex.Next(); tok.Kind != jslex.EOF; tok = lex.Next() {
+		if tok.Kind != jslex.Comment {
+			toks = append(toks, tok)
+		}
+	}
+	p := &chunkParser{src: content, toks: toks}
+
+	var out []chunkFunc
+	for i := range toks {
+		param, body, assignedU := p.functionAt(i)
+		if body < 0 {
+			continue
+		}
+		parts, ok := p.concat(body, param)
+		if !ok {
+			continue
+		}
+		fn := chunkFunc{parts: parts}
+		hasLookup := false
+		for _, part := range parts {
+			if part.lookup != nil {
+				hasLookup = true
+				for id := range part.lookup {
+					fn.ids = append(fn.ids, id)
+				}
+			}
+		}
+		if !hasLookup && !assignedU {
+			continue
+		}
+		sort.Strings(fn.ids)
+		out = append(out, fn)
+	}
+	return out
+}
+
+type chunkParser struct {
+	src  []byte
+	toks []jslex.Token
+}
+
+// is reports whether token i is s, without allocating
+func (p *chunkParser) is(i int, s string) bool {
+	return i >= 0 && i < len(p.toks) && string(p.src[p.toks[i].Start:p.toks[i].End]) == s
+}
+
+func (p *chunkParser) text(i int) string {
+	if i < 0 || i >= len(p.toks) {
+		return ""
+	}
+	return string(p.src[p.toks[i].Start:p.toks[i].End])
+}
+
+// functionAt recognizes `function(e){return ` and `e=>` / `(e)=>` / `e=>{return `
+// starting at token i. It returns the parameter name and the index of the
+// first token of the returned expression, or -1.
+func (p *chunkParser) functionAt(i int) (param string, body int, assignedU bool) {
+	assignedU = p.is(i-3, ".") && p.is(i-2, "u") && p.is(i-1, "=")
+	skipReturn := func(j int) int {
+		if p.is(j, "{") && p.is(j+1, "return") {
+			return j + 2
+		}
+		return -1
+	}
+
+	switch {
+	case p.is(i, "function"):
+		j := i + 1
+		if p.toks[min(j, len(p.toks)-1)].Kind == jslex.Ident && !p.is(j, "(") {
+			j++ // Named function
+		}
+		if !p.is(j, "(") || !p.is(j+2, ")") || p.toks[min(j+1, len(p.toks)-1)].Kind != jslex.Ident {
+			return "", -1, false
+		}
+		return p.text(j + 1), skipReturn(j + 3), assignedU
+	case p.is(i, "(") && p.is(i+2, ")") && p.is(i+3, "=>"):
+		i++
+		fallthrough
+	case p.toks[i].Kind == jslex.Ident && p.is(i+1, "=>") || p.is(i+1, ")") && p.is(i+2, "=>"):
+		if p.toks[i].Kind != jslex.Ident {
+			return "", -1, false
+		}
+		arrow := i + 1
+		if p.is(arrow, ")") {
+			arrow++
+		}
+		if p.is(arrow+1, "{") {
+			return p.text(i), skipReturn(arrow + 1), assignedU
+		}
+		return p.text(i), arrow + 1, assignedU
+	}
+	return "", -1, false
+}
+
+// concat parses `part + part + ...` up to the end of the expression
+func (p *chunkParser) concat(i int, param string) ([]chunkPart, bool) {
+	var parts []chunkPart
+	for {
+		part, next, ok := p.part(i, param)
+		if !ok {
+			return nil, false
+		}
+		parts = append(parts, part)
+		if !p.is(next, "+") {
+			switch p.text(next) {
+			case ";", "}", ",", ")", "":
+				return parts, len(parts) > 1
+			}
+			return nil, false
+		}
+		i = next + 1
+	}
+}
+
+// part parses one operand starting at token i and returns the index after it
+func (p *chunkParser) part(i int, param string) (chunkPart, int, bool) {
+	if i < 0 || i >= len(p.toks) {
+		return chunkPart{}, i, false
+	}
+	tok := p.toks[i]
+	switch {
+	case tok.Kind == jslex.String:
+		v, ok := jslex.Unquote(p.src[tok.Start:tok.End])
+		return chunkPart{literal: string(v)}, i + 1, ok
+	case tok.Kind == jslex.Ident && p.is(i, param):
+		return chunkPart{isID: true}, i + 1, true
+	case tok.Kind == jslex.Ident && p.is(i+1, ".") && p.is(i+2, "p"):
+		return chunkPart{publicPath: true}, i + 3, true
+	case p.is(i, "{"):
+		table, next, ok := p.table(i)
+		if !ok || !p.is(next, "[") || !p.is(next+1, param) || !p.is(next+2, "]") {
+			return chunkPart{}, i, false
+		}
+		// An empty name table ({}[id] || id) is only useful with its fallback
+		if len(table) == 0 && !(p.is(next+3, "||") && p.is(next+4, param)) {
+			return chunkPart{}, i, false
+		}
+		return chunkPart{lookup: table}, next + 3, true
+	case p.is(i, "("):
+		inner, next, ok := p.part(i+1, param)
+		if !ok {
+			return chunkPart{}, i, false
+		}
+		if p.is(next, "||") && p.is(next+1, param) && inner.lookup != nil {
+			inner.idFallback = true
+			next += 2
+		}
+		if !p.is(next, ")") {
+			return chunkPart{}, i, false
+		}
+		return inner, next + 1, true
+	}
+	return chunkPart{}, i, false
+}
+
+// table parses an object literal of constant keys and string values. It
+// may be empty: webpack 4 emits {}[id] when no chunk has a name.
+func (p *chunkParser) table(i int) (map[string]string, int, bool) {
+	table := make(map[string]string)
+	j := i + 1
+	for !p.is(j, "}") {
+		if j+2 >= len(p.toks) || !p.is(j+1, ":") || p.toks[j+2].Kind != jslex.String {
+			return nil, i, false
+		}
+		key := p.text(j)
+		if p.toks[j].Kind == jslex.String {
+			k, _ := jslex.Unquote([]byte(key))
+			key = string(k)
+		}
+		v, _ := jslex.Unquote(p.src[p.toks[j+2].Start:p.toks[j+2].End])
+		table[key] = string(v)
+		j += 3
+		if p.is(j, ",") {
+			j++
+		}
+	}
+	return table, j + 1, true
+}
+
+// viteDeps returns the JS files listed by Vite's __vite__mapDeps helper
+func viteDeps(content []byte) []string {
+	k := bytes.Index(content, []byte("__vite__mapDeps"))
+	if k < 0 {
+		return nil
+	}
+	open := bytes.IndexByte(content[k:], '[')
+	if open < 0 {
+		return nil
+	}
+	lex := jslex.New(content[k+open+1:])
+	var deps []string
+	for tok := lex.Next(); tok.Kind == jslex.String || tok.Kind == jslex.Punct && string(lex.Text(tok)) == ","; tok = lex.Next() {
+		if tok.Kind != jslex.String {
+			continue
+		}
+		v, ok := jslex.Unquote(lex.Text(tok))
+		if ok && (bytes.HasSuffix(v, []byte(".js")) || bytes.HasSuffix(v, []byte(".mjs"))) {
+			deps = append(deps, string(v))
+		}
+	}
+	return deps
+}
+
+// resolveChunk turns a chunk path into a URL. An explicit public path is
+// used as given. Otherwise ("auto", "" or relative) the path is resolved
+// against the bundle URL's prefix up to the chunk path's first directory,
+// e.g. https://x/app/static/js/main.js + static/js/1.js ->
+// https://x/app/static/js/1.js, or against the bundle's directory.
+func resolveChunk(bundleURL, publicPath, path string) string {
+	if publicPath != "auto" && publicPath != "" && !strings.HasPrefix(publicPath, ".") {
+		u, _ := resolveURL(bundleURL, publicPath+path)
+		return u
+	}
+	path = strings.TrimPrefix(path, "./")
+
+	base, err := url.Parse(bundleURL)
+	if err != nil {
+		return ""
+	}
+	if slash := strings.IndexByte(path, '/'); slash > 0 && !strings.Contains(path, "://") {
+		if k := strings.LastIndex(base.Path, "/"+path[:slash+1]); k >= 0 {
+			u, _ := resolveURL(bundleURL, base.Path[:k+1]+path)
+			return u
+		}
+	}
+	u, _ := resolveURL(bundleURL, path)
+	return u
+}
+
+func resolveURL(base, ref string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return b.ResolveReference(r).String(), nil
+}
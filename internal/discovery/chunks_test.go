@@ -0,0 +1,53 @@
+package discovery
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestChunkURLs(t *testing.T) {
+	const bundle = "https://example.com/static/js/main.js"
+	tests := []struct {
+		name    string
+		runtime string
+		want    []string
+	}{
+		{
+			name:    "webpack 4 jsonpScriptSrc without chunk names",
+			runtime: `!function(e){function c(e){return a.p+"static/js/"+({}[e]||e)+"."+{0:"abc",1:"def"}[e]+".chunk.js"}}()`,
+			want: []string{
+				"https://example.com/static/js/0.abc.chunk.js",
+				"https://example.com/static/js/1.def.chunk.js",
+			},
+		},
+		{
+			name:    "webpack 4 jsonpScriptSrc with chunk names",
+			runtime: `!function(e){function c(e){return a.p+"static/js/"+({2:"named"}[e]||e)+"."+{0:"abc",2:"def"}[e]+".chunk.js"}}()`,
+			want: []string{
+				"https://example.com/static/js/0.abc.chunk.js",
+				"https://example.com/static/js/named.def.chunk.js",
+			},
+		},
+		{
+			name:    "webpack 5 arrow function",
+			runtime: `r.u=e=>({216:"vendors"}[e]||e)+"."+{216:"aa",592:"bb"}[e]+".js"`,
+			want: []string{
+				"https://example.com/static/js/vendors.aa.js",
+				"https://example.com/static/js/592.bb.js",
+			},
+		},
+		{
+			name:    "empty name table without fallback",
+			runtime: `function c(e){return "static/js/"+{}[e]+".chunk.js"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ChunkURLs([]byte(tt.runtime), bundle)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ChunkURLs = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
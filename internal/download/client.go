@@ -8,9 +8,23 @@ import (
 	"time"
 )
 
+// FetchResult describes the response a download was written from
+type FetchResult struct {
+	Status    int
+	Size      int64
+	Header    http.Header // nil when the file already existed
+	FetchedAt time.Time
+}
+
 // DownloadFile downloads a file from URL with retry logic and rate limiting
 // Returns: success, statusCode, sizeBytes, error
 func DownloadFile(url, outputPath string, timeout int, retry int) (bool, int, int64, error) {
+	ok, res, err := Fetch(url, outputPath, timeout, retry)
+	return ok, res.Status, res.Size, err
+}
+
+// Fetch is DownloadFile returning the response details as well
+func Fetch(url, outputPath string, timeout int, retry int) (bool, FetchResult, error) {
 	// Connection timeout only - downloads complete regardless of size
 	connectionTimeout := 30 * time.Second
 	if timeout > 30 {
@@ -33,7 +47,7 @@ func DownloadFile(url, outputPath string, timeout int, retry int) (bool, int, in
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return false, 0, 0, fmt.Errorf("failed to create request: %w", err)
+		return false, FetchResult{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers to mimic browser behavior
@@ -48,39 +62,40 @@ func DownloadFile(url, outputPath string, timeout int, retry int) (bool, int, in
 		// Retry on connection error
 		if retry < 2 {
 			time.Sleep(time.Duration(2<<uint(retry)) * time.Second) // Exponential backoff
-			return DownloadFile(url, outputPath, timeout, retry+1)
+			return Fetch(url, outputPath, timeout, retry+1)
 		}
-		return false, 0, 0, fmt.Errorf("request failed: %w", err)
+		return false, FetchResult{}, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	statusCode := resp.StatusCode
+	res := FetchResult{Status: statusCode, Header: resp.Header, FetchedAt: time.Now()}
 
 	// Handle rate limiting and server errors with retry
 	if (statusCode == 429 || statusCode == 503 || statusCode == 504) && retry < 2 {
 		backoff := time.Duration(1<<uint(retry)) * time.Second // Exponential backoff: 2^retry
 		time.Sleep(backoff)
-		return DownloadFile(url, outputPath, timeout, retry+1)
+		return Fetch(url, outputPath, timeout, retry+1)
 	}
 
 	// Only accept successful status codes
 	if statusCode < 200 || statusCode >= 300 {
 		// Special handling for 304 Not Modified
 		if statusCode == 304 {
-			return true, statusCode, 0, nil
+			return true, res, nil
 		}
-		return false, statusCode, 0, fmt.Errorf("HTTP %d", statusCode)
+		return false, res, fmt.Errorf("HTTP %d", statusCode)
 	}
 
 	// Create output file (Prevent Overwrite - Rule 7)
 	// Check if file exists and is not empty
 	if info, err := os.Stat(outputPath); err == nil && info.Size() > 0 {
-		return true, 304, info.Size(), nil // Treat as "Not Modified" / Already done
+		return true, FetchResult{Status: 304, Size: info.Size()}, nil // Treat as "Not Modified" / Already done
 	}
 
 	outFile, err := os.Create(outputPath)
 	if err != nil {
-		return false, statusCode, 0, fmt.Errorf("failed to create file: %w", err)
+		return false, res, fmt.Errorf("failed to create file: %w", err)
 	}
 	defer outFile.Close()
 
@@ -88,14 +103,15 @@ func DownloadFile(url, outputPath string, timeout int, retry int) (bool, int, in
 	written, err := io.Copy(outFile, resp.Body)
 	if err != nil {
 		os.Remove(outputPath) // Clean up partial file
-		return false, statusCode, 0, fmt.Errorf("failed to write file: %w", err)
+		return false, res, fmt.Errorf("failed to write file: %w", err)
 	}
 
 	// Verify file size
 	if written == 0 {
 		os.Remove(outputPath) // Clean up empty file
-		return false, statusCode, 0, fmt.Errorf("empty file downloaded")
+		return false, res, fmt.Errorf("empty file downloaded")
 	}
 
-	return true, statusCode, written, nil
+	res.Size = written
+	return true, res, nil
 }
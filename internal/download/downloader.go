@@ -10,12 +10,14 @@ import (
 
 	"github.com/shaniidev/keyana/internal/config"
 	"github.com/shaniidev/keyana/internal/core"
+	"github.com/shaniidev/keyana/internal/manifest"
 	"github.com/shaniidev/keyana/internal/ui"
 )
 
 type Downloader struct {
 	Config        *config.Config
-	downloadedMap map[string]string // URL -> filename mapping
+	Manifest      *manifest.Manifest // Records every downloaded file, if set
+	downloadedMap map[string]string  // URL -> filename mapping
 	mapMu         sync.RWMutex
 }
 
@@ -107,10 +109,12 @@ func (d *Downloader) Run(urls []string) []*core.JSFile {
 	for _, url := range urls {
 		if exists, filename := d.isAlreadyDownloaded(url); exists {
 			skipped++
+			localPath := filepath.Join(rawDir, filename)
+			d.Manifest.Ensure(localPath, url)
 			alreadyDownloaded = append(alreadyDownloaded, &core.JSFile{
 				URL:        url,
 				Filename:   filename,
-				LocalPath:  filepath.Join(rawDir, filename),
+				LocalPath:  localPath,
 				Downloaded: true,
 			})
 		} else {
@@ -201,7 +205,11 @@ func (d *Downloader) worker(id int, jobs <-chan downloadJob, results chan<- *cor
 		outputPath := filepath.Join(rawDir, filename)
 
 		// Attempt download
-		success, statusCode, size, err := DownloadFile(job.url, outputPath, d.Config.Timeout, 0)
+		success, res, err := Fetch(job.url, outputPath, d.Config.Timeout, 0)
+		statusCode, size := res.Status, res.Size
+		if success {
+			d.record(job.url, outputPath, res)
+		}
 
 		// Update statistics
 		mu.Lock()
@@ -253,6 +261,23 @@ func (d *Downloader) worker(id int, jobs <-chan downloadJob, results chan<- *cor
 	}
 }
 
+// record adds a downloaded file to the manifest. Files that already existed
+// have no response to record and are only hashed.
+func (d *Downloader) record(url, path string, res FetchResult) {
+	if res.Header == nil {
+		d.Manifest.Ensure(path, url)
+		return
+	}
+	fetched := res.FetchedAt
+	d.Manifest.Record(manifest.Entry{
+		Path:      path,
+		URL:       url,
+		Status:    res.Status,
+		FetchedAt: &fetched,
+		Headers:   res.Header,
+	})
+}
+
 // downloadJob represents a single download task
 type downloadJob struct {
 	url   string
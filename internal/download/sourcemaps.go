@@ -0,0 +1,152 @@
+package download
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/shaniidev/keyana/internal/core"
+	"github.com/shaniidev/keyana/internal/sourcemap"
+	"github.com/shaniidev/keyana/internal/ui"
+)
+
+// FetchSourceMaps finds the source map of every downloaded bundle, from its
+// sourceMappingURL comment or a .map sibling, and unpacks the embedded
+// original sources under OutputDir/sources/<bundle>/. Bundles loaded from
+// disk (-raw) only use maps that exist locally.
+func (d *Downloader) FetchSourceMaps(files []*core.JSFile) []core.SourceFile {
+	mapsDir := filepath.Join(d.Config.OutputDir, "js_files", "maps")
+	sourcesDir := filepath.Join(d.Config.OutputDir, "sources")
+	os.MkdirAll(mapsDir, 0755)
+
+	logPath := filepath.Join(d.Config.OutputDir, "logs", "sourcemaps.log")
+	logFile, _ := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	defer logFile.Close()
+
+	var bundles []*core.JSFile
+	for _, f := range files {
+		if f.Downloaded {
+			bundles = append(bundles, f)
+		}
+	}
+	if len(bundles) == 0 {
+		return nil
+	}
+
+	var (
+		mu      sync.Mutex
+		sources []core.SourceFile
+		found   int
+	)
+	sem := make(chan struct{}, max(d.Config.Concurrency, 1))
+	var wg sync.WaitGroup
+	bar := ui.NewProgressBar(len(bundles), "Source maps")
+
+	for _, file := range bundles {
+		wg.Add(1)
+		go func(f *core.JSFile) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			defer bar.Increment()
+
+			mapURL, mapPath, data, err := d.loadSourceMap(f, mapsDir)
+			if err != nil || data == nil {
+				if err != nil {
+					mu.Lock()
+					fmt.Fprintf(logFile, "[ERROR] %s: %v\n", f.Filename, err)
+					mu.Unlock()
+				}
+				return
+			}
+
+			m, err := sourcemap.Parse(data)
+			var unpacked []sourcemap.Source
+			if err == nil {
+				dir := filepath.Join(sourcesDir, strings.TrimSuffix(f.Filename, filepath.Ext(f.Filename)))
+				unpacked, err = sourcemap.Unpack(m, dir)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				fmt.Fprintf(logFile, "[ERROR] %s (%s): %v\n", f.Filename, mapURL, err)
+				return
+			}
+			f.SourceMapURL = mapURL
+			found++
+			fmt.Fprintf(logFile, "[OK] %s: %d sources from %s\n", f.Filename, len(unpacked), mapURL)
+			for _, src := range unpacked {
+				d.Manifest.Derive(src.LocalPath, mapPath)
+				sources = append(sources, core.SourceFile{
+					LocalPath: src.LocalPath,
+					Name:      src.Name,
+					MapURL:    mapURL,
+					BundleURL: f.URL,
+				})
+			}
+		}(file)
+	}
+	wg.Wait()
+
+	fmt.Println()
+	ui.Printf(ui.Green, "[+] Source Maps: %d of %d bundles, %d original sources unpacked\n", found, len(bundles), len(sources))
+	fmt.Printf("[+] Source map log saved: %s\n", logPath)
+	return sources
+}
+
+// loadSourceMap returns the map of a bundle, where it came from and the
+// local file holding it (the bundle itself for inline maps). It returns no
+// data and no error when the bundle has no reachable map.
+func (d *Downloader) loadSourceMap(f *core.JSFile, mapsDir string) (string, string, []byte, error) {
+	content, err := os.ReadFile(f.LocalPath)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	ref := sourcemap.FindURL(content, f.URL)
+	if strings.HasPrefix(ref, "data:") {
+		data, err := sourcemap.DecodeDataURL(ref)
+		return f.URL + " (inline)", f.LocalPath, data, err
+	}
+
+	// Local bundles: only a map next to the file can be used
+	if f.URL == "" {
+		candidate := f.LocalPath + ".map"
+		if ref != "" && !strings.Contains(ref, "://") {
+			candidate = filepath.Join(filepath.Dir(f.LocalPath), filepath.FromSlash(ref))
+		}
+		data, err := os.ReadFile(candidate)
+		if err != nil {
+			return "", "", nil, nil
+		}
+		d.Manifest.Ensure(candidate, "")
+		return candidate, candidate, data, nil
+	}
+
+	// No comment: probe the conventional sibling
+	mapURL := ref
+	if mapURL == "" {
+		mapURL = sourcemap.SiblingURL(f.URL)
+	}
+	if !strings.HasPrefix(mapURL, "http://") && !strings.HasPrefix(mapURL, "https://") {
+		return "", "", nil, fmt.Errorf("unsupported source map URL %s", mapURL)
+	}
+
+	outPath := filepath.Join(mapsDir, f.Filename+".map")
+	ok, res, err := Fetch(mapURL, outPath, d.Config.Timeout, 0)
+	if !ok {
+		if ref == "" && res.Status == 404 {
+			return "", "", nil, nil // Probe miss, not worth logging
+		}
+		return "", "", nil, fmt.Errorf("fetch %s: %v", mapURL, err)
+	}
+	d.record(mapURL, outPath, res)
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return mapURL, outPath, data, nil
+}
@@ -0,0 +1,432 @@
+// Package jslex splits JavaScript source into tokens. It is not a parser:
+// it knows just enough (regex vs division, template literals, comments) to
+// never misread a string, which is what secret scanning and formatting need.
+package jslex
+
+import (
+	"bytes"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Kind is the type of a token
+type Kind uint8
+
+const (
+	EOF Kind = iota
+	Ident
+	Number
+	String   // '...' or "..."
+	Template // `...`, including any ${...} substitutions
+	Regex
+	Punct
+	Comment
+)
+
+// Token is a span of the source. NewlineBefore is set when a line break
+// precedes the token, which is what automatic semicolon insertion keys on.
+type Token struct {
+	Kind          Kind
+	NewlineBefore bool
+	Start, End    int
+}
+
+// Lexer tokenizes one source buffer
+type Lexer struct {
+	src  []byte
+	pos  int
+	prev Token // Last non-comment token, to tell regex from division
+
+	parens    []bool // Open '(' that follow if, while, for or with
+	condClose bool   // prev is the ')' of such a condition
+}
+
+// New returns a lexer over src
+func New(src []byte) *Lexer {
+	return &Lexer{src: src}
+}
+
+// Text returns the source of tok
+func (l *Lexer) Text(tok Token) []byte {
+	return l.src[tok.Start:tok.End]
+}
+
+// Next returns the next token, or a token of Kind EOF at the end. Malformed
+// input (an unterminated string, say) is returned as a token running to the
+// end of the line or buffer rather than an error.
+func (l *Lexer) Next() Token {
+	newline := l.skipSpace()
+	if l.pos >= len(l.src) {
+		return Token{Kind: EOF, NewlineBefore: newline, Start: l.pos, End: l.pos}
+	}
+
+	start := l.pos
+	c := l.src[l.pos]
+	var kind Kind
+	switch {
+	case c == '/' && l.peek(1) == '/':
+		kind = Comment
+		l.pos = lineEnd(l.src, l.pos)
+	case c == '/' && l.peek(1) == '*':
+		kind = Comment
+		if end := bytes.Index(l.src[l.pos+2:], []byte("*/")); end >= 0 {
+			l.pos += end + 4
+		} else {
+			l.pos = len(l.src)
+		}
+	case c == '/' && l.regexAllowed():
+		kind = Regex
+		l.scanRegex()
+	case c == '"' || c == '\'':
+		kind = String
+		l.pos = stringEnd(l.src, l.pos)
+	case c == '`':
+		kind = Template
+		l.pos = templateEnd(l.src, l.pos)
+	case isDigit(c) || c == '.' && isDigit(l.peek(1)):
+		kind = Number
+		l.scanNumber()
+	case isIdentStart(c):
+		kind = Ident
+		l.scanIdent()
+	default:
+		kind = Punct
+		l.pos += punctLen(l.src[l.pos:])
+	}
+
+	tok := Token{Kind: kind, NewlineBefore: newline, Start: start, End: l.pos}
+	if kind != Comment {
+		l.trackParens(tok)
+		l.prev = tok
+	}
+	return tok
+}
+
+func (l *Lexer) peek(n int) byte {
+	if l.pos+n < len(l.src) {
+		return l.src[l.pos+n]
+	}
+	return 0
+}
+
+// skipSpace advances past whitespace and reports whether it held a newline
+func (l *Lexer) skipSpace() bool {
+	newline := false
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case '\n', '\r':
+			newline = true
+		case ' ', '\t', '\f', '\v':
+		default:
+			// U+2028/U+2029 are line terminators, U+00A0 and U+FEFF space
+			r, size := utf8.DecodeRune(l.src[l.pos:])
+			switch r {
+			case '\u2028', '\u2029':
+				newline = true
+			case '\u00a0', '\ufeff':
+			default:
+				return newline
+			}
+			l.pos += size
+			continue
+		}
+		l.pos++
+	}
+	return newline
+}
+
+// exprKeywords are keywords after which an expression, and so a regex, may start
+var exprKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true,
+	"new": true, "delete": true, "void": true, "throw": true, "case": true,
+	"do": true, "else": true, "yield": true, "await": true,
+}
+
+// regexAllowed reports whether a '/' here starts a regex literal rather
+// than a division, judging by the previous token
+func (l *Lexer) regexAllowed() bool {
+	switch l.prev.Kind {
+	case EOF:
+		return true
+	case Number, String, Template, Regex:
+		return false
+	case Ident:
+		return exprKeywords[string(l.Text(l.prev))]
+	}
+	switch string(l.Text(l.prev)) {
+	case ")":
+		return l.condClose
+	case "]", "}", "++", "--":
+		return false
+	}
+	return true
+}
+
+// trackParens records which parentheses close a statement condition, after
+// which a '/' starts a regex: if (x) /re/.test(y)
+func (l *Lexer) trackParens(tok Token) {
+	l.condClose = false
+	if tok.Kind != Punct || tok.End-tok.Start != 1 {
+		return
+	}
+	switch l.src[tok.Start] {
+	case '(':
+		cond := false
+		if l.prev.Kind == Ident {
+			switch string(l.Text(l.prev)) {
+			case "if", "while", "for", "with":
+				cond = true
+			}
+		}
+		l.parens = append(l.parens, cond)
+	case ')':
+		if n := len(l.parens); n > 0 {
+			l.condClose = l.parens[n-1]
+			l.parens = l.parens[:n-1]
+		}
+	}
+}
+
+func (l *Lexer) scanRegex() {
+	inClass := false
+	for l.pos++; l.pos < len(l.src); l.pos++ {
+		switch l.src[l.pos] {
+		case '\\':
+			l.pos++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '\n':
+			return
+		case '/':
+			if !inClass {
+				l.pos++
+				for l.pos < len(l.src) && isIdentPart(l.src[l.pos]) {
+					l.pos++ // Flags
+				}
+				return
+			}
+		}
+	}
+	if l.pos > len(l.src) {
+		l.pos = len(l.src)
+	}
+}
+
+func (l *Lexer) scanNumber() {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case isIdentPart(c) || c == '.':
+			l.pos++
+		case (c == '+' || c == '-') && (l.src[l.pos-1] == 'e' || l.src[l.pos-1] == 'E') && !isHexNumber(l.src, l.pos):
+			l.pos++
+		default:
+			return
+		}
+	}
+}
+
+// isHexNumber reports whether the number ending before pos is 0x..., where
+// 'e' is a digit rather than an exponent
+func isHexNumber(src []byte, pos int) bool {
+	i := pos
+	for i > 0 && isIdentPart(src[i-1]) {
+		i--
+	}
+	return pos-i > 1 && src[i] == '0' && (src[i+1] == 'x' || src[i+1] == 'X')
+}
+
+func (l *Lexer) scanIdent() {
+	for l.pos < len(l.src) && isIdentPart(l.src[l.pos]) {
+		if l.src[l.pos] >= utf8.RuneSelf {
+			// Non-ASCII letters are identifier parts, the spaces skipSpace knows are not
+			switch r, _ := utf8.DecodeRune(l.src[l.pos:]); r {
+			case '\u2028', '\u2029', '\u00a0', '\ufeff':
+				return
+			}
+		}
+		l.pos++
+	}
+}
+
+// stringEnd returns the offset just past the quoted string at start. An
+// unterminated string ends at the line break.
+func stringEnd(src []byte, start int) int {
+	q := src[start]
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '\n':
+			return i
+		case q:
+			return i + 1
+		}
+	}
+	return len(src)
+}
+
+// templateEnd returns the offset just past the template literal at start,
+// skipping over nested strings, templates and braces in substitutions
+func templateEnd(src []byte, start int) int {
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '`':
+			return i + 1
+		case '$':
+			if i+1 < len(src) && src[i+1] == '{' {
+				i = substitutionEnd(src, i+2) - 1
+			}
+		}
+	}
+	return len(src)
+}
+
+// substitutionEnd returns the offset just past the '}' closing a ${
+func substitutionEnd(src []byte, i int) int {
+	depth := 1
+	for i < len(src) {
+		switch src[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		case '"', '\'':
+			i = stringEnd(src, i)
+			continue
+		case '`':
+			i = templateEnd(src, i)
+			continue
+		}
+		i++
+	}
+	return len(src)
+}
+
+func lineEnd(src []byte, i int) int {
+	if n := bytes.IndexByte(src[i:], '\n'); n >= 0 {
+		return i + n
+	}
+	return len(src)
+}
+
+// puncts lists multi-byte punctuators, longest first
+var puncts = []string{
+	">>>=", "...", "===", "!==", "**=", "<<=", ">>=", ">>>", "&&=", "||=", "??=",
+	"=>", "==", "!=", "<=", ">=", "&&", "||", "??", "?.", "++", "--", "+=", "-=",
+	"*=", "/=", "%=", "&=", "|=", "^=", "**", "<<", ">>",
+}
+
+func punctLen(b []byte) int {
+	for _, p := range puncts {
+		if bytes.HasPrefix(b, []byte(p)) {
+			// a?.5:b is a conditional, not optional chaining
+			if p == "?." && len(b) > 2 && isDigit(b[2]) {
+				continue
+			}
+			return len(p)
+		}
+	}
+	if b[0] >= utf8.RuneSelf {
+		_, size := utf8.DecodeRune(b)
+		return size
+	}
+	return 1
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '$' || c == '\\' || c >= utf8.RuneSelf
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+// Unquote returns the value of a string literal token, or of a template
+// literal without substitutions
+func Unquote(lit []byte) ([]byte, bool) {
+	if len(lit) < 2 || lit[0] != lit[len(lit)-1] {
+		return nil, false
+	}
+	body := lit[1 : len(lit)-1]
+	if lit[0] == '`' && bytes.Contains(body, []byte("${")) {
+		return nil, false
+	}
+	return Unescape(body)
+}
+
+// Unescape resolves the escape sequences of a string literal body
+func Unescape(s []byte) ([]byte, bool) {
+	if bytes.IndexByte(s, '\\') < 0 {
+		return s, true
+	}
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			out = append(out, s[i])
+			continue
+		}
+		i++
+		switch c := s[i]; c {
+		case 'x':
+			if i+2 >= len(s) {
+				return nil, false
+			}
+			v, err := strconv.ParseUint(string(s[i+1:i+3]), 16, 8)
+			if err != nil {
+				return nil, false
+			}
+			out = append(out, byte(v))
+			i += 2
+		case 'u':
+			var digits []byte
+			if i+1 < len(s) && s[i+1] == '{' {
+				j := bytes.IndexByte(s[i:], '}')
+				if j < 0 {
+					return nil, false
+				}
+				digits, i = s[i+2:i+j], i+j
+			} else {
+				if i+4 >= len(s) {
+					return nil, false
+				}
+				digits, i = s[i+1:i+5], i+4
+			}
+			v, err := strconv.ParseUint(string(digits), 16, 32)
+			if err != nil {
+				return nil, false
+			}
+			out = utf8.AppendRune(out, rune(v))
+		case 'n':
+			out = append(out, '\n')
+		case 't':
+			out = append(out, '\t')
+		case 'r':
+			out = append(out, '\r')
+		case 'b':
+			out = append(out, '\b')
+		case 'f':
+			out = append(out, '\f')
+		case 'v':
+			out = append(out, '\v')
+		case '0':
+			out = append(out, 0)
+		case '\n':
+			// Line continuation
+		default:
+			out = append(out, c)
+		}
+	}
+	return out, true
+}
@@ -0,0 +1,125 @@
+package jslex
+
+import (
+	"slices"
+	"testing"
+)
+
+// tokens returns the kind and text of every token of src
+func tokens(src string) ([]Kind, []string) {
+	l := New([]byte(src))
+	var kinds []Kind
+	var texts []string
+	for {
+		tok := l.Next()
+		if tok.Kind == EOF {
+			return kinds, texts
+		}
+		kinds = append(kinds, tok.Kind)
+		texts = append(texts, string(l.Text(tok)))
+	}
+}
+
+func TestRegexOrDivision(t *testing.T) {
+	tests := []struct {
+		src   string
+		regex string // "" = no regex token
+	}{
+		{`/ab+c/g.test(x)`, `/ab+c/g`},
+		{`x = /[/]"/.source`, `/[/]"/`},
+		{`f(a, /\//i)`, `/\//i`},
+		{`return /key/.test(s)`, `/key/`},
+		{`typeof /x/`, `/x/`},
+		{`if (ok) /re/.exec(s)`, `/re/`},
+		{`while (i--) /re/g.lastIndex = 0`, `/re/g`},
+		{`a = b / c / d`, ``},
+		{`a = 10 / 2 / 5`, ``},
+		{`a = f(x) / 2 / y`, ``},
+		{`a = arr[0] / 2 / y`, ``},
+		{`a = x++ / 2 / y`, ``},
+		{`a = "s" / 2 / y`, ``},
+		{`a = {}.n / 2 / y`, ``},
+	}
+	for _, tt := range tests {
+		kinds, texts := tokens(tt.src)
+		var regex string
+		for i, k := range kinds {
+			if k == Regex {
+				regex = texts[i]
+			}
+		}
+		if regex != tt.regex {
+			t.Errorf("%s: regex %q, want %q", tt.src, regex, tt.regex)
+		}
+	}
+}
+
+func TestTemplatesStringsAndComments(t *testing.T) {
+	tests := []struct {
+		src  string
+		kind Kind
+		want string
+	}{
+		{"x = `a ${b} c`;", Template, "`a ${b} c`"},
+		{"x = `a ${ {k: `}`}.k } c`;", Template, "`a ${ {k: `}`}.k } c`"},
+		{"x = `a ${f(\"`\")} c`;", Template, "`a ${f(\"`\")} c`"},
+		{"x = `line\none`;", Template, "`line\none`"},
+		{"x = `esc \\` still`;", Template, "`esc \\` still`"},
+		{`x = "a\"b";`, String, `"a\"b"`},
+		{`x = 'it\'s // not a comment';`, String, `'it\'s // not a comment'`},
+		{"x = \"unterminated\ny = 1", String, `"unterminated`},
+		{"x = 1 // line /comment/\ny", Comment, "// line /comment/"},
+		{"x = 1 /* block \"quote\" */ y", Comment, `/* block "quote" */`},
+		{"x = 1 /* unterminated", Comment, "/* unterminated"},
+	}
+	for _, tt := range tests {
+		kinds, texts := tokens(tt.src)
+		var got []string
+		for i, k := range kinds {
+			if k == tt.kind {
+				got = append(got, texts[i])
+			}
+		}
+		if !slices.Equal(got, []string{tt.want}) {
+			t.Errorf("%q: %v tokens %q, want [%q]", tt.src, tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestNewlineBefore(t *testing.T) {
+	l := New([]byte("a\nb /* c */ d\u2028e")) // U+2028 is a line terminator too
+	var got []bool
+	for tok := l.Next(); tok.Kind != EOF; tok = l.Next() {
+		if tok.Kind != Comment {
+			got = append(got, tok.NewlineBefore)
+		}
+	}
+	if want := []bool{false, true, false, true}; !slices.Equal(got, want) {
+		t.Errorf("newlines %v, want %v", got, want)
+	}
+}
+
+func TestUnquote(t *testing.T) {
+	tests := []struct {
+		lit  string
+		want string
+		ok   bool
+	}{
+		{`"plain"`, "plain", true},
+		{`'sk\_live\x5fA\u{1F511}'`, "sk_live_A\U0001F511", true},
+		{`"tab\tnl\nnul\0"`, "tab\tnl\nnul\x00", true},
+		{"\"cont\\\ninued\"", "continued", true},
+		{"`no subst`", "no subst", true},
+		{"`a ${b}`", "", false},
+		{`"bad \xZZ"`, "", false},
+		{`"short \u12"`, "", false},
+		{`"open \u{41"`, "", false},
+		{`"mismatched'`, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Unquote([]byte(tt.lit))
+		if ok != tt.ok || string(got) != tt.want {
+			t.Errorf("Unquote(%s) = %q, %v; want %q, %v", tt.lit, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Package manifest records where every local file of a run came from
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileName is the manifest file inside the output directory
+const FileName = "manifest.json"
+
+// Entry describes one local file. URL and SHA256 identify the content as it
+// was fetched: a beautified copy or an unpacked source carries the URL and
+// hash of the file it was produced from, named by DerivedFrom.
+type Entry struct {
+	Path        string      `json:"path"`
+	URL         string      `json:"url,omitempty"`
+	SHA256      string      `json:"sha256"`
+	Status      int         `json:"status,omitempty"`
+	FetchedAt   *time.Time  `json:"fetched_at,omitempty"`
+	Headers     http.Header `json:"headers,omitempty"`
+	DerivedFrom string      `json:"derived_from,omitempty"`
+}
+
+// Manifest is the set of entries of an output directory, keyed by local
+// path. It is safe for concurrent use; a nil Manifest records nothing.
+type Manifest struct {
+	path    string
+	mu      sync.RWMutex
+	entries map[string]*Entry
+}
+
+// Load reads the manifest of an output directory. A missing file yields an
+// empty manifest.
+func Load(outputDir string) (*Manifest, error) {
+	m := &Manifest{path: filepath.Join(outputDir, FileName), entries: make(map[string]*Entry)}
+	data, err := os.ReadFile(m.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return m, err
+	}
+	var entries []*Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return m, err
+	}
+	for _, e := range entries {
+		m.entries[e.Path] = e
+	}
+	return m, nil
+}
+
+// Path returns where the manifest is saved
+func (m *Manifest) Path() string {
+	return m.path
+}
+
+// Len returns the number of entries
+func (m *Manifest) Len() int {
+	if m == nil {
+		return 0
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.entries)
+}
+
+// Record adds or replaces the entry of e.Path. The hash is computed from
+// the file when e has none.
+func (m *Manifest) Record(e Entry) error {
+	if m == nil {
+		return nil
+	}
+	if e.SHA256 == "" {
+		sum, err := HashFile(e.Path)
+		if err != nil {
+			return err
+		}
+		e.SHA256 = sum
+	}
+	m.mu.Lock()
+	m.entries[e.Path] = &e
+	m.mu.Unlock()
+	return nil
+}
+
+// Ensure records a file that was not fetched in this run (loaded with -raw
+// or from a previous session), keeping an existing entry whose hash still
+// matches
+func (m *Manifest) Ensure(path, url string) error {
+	if m == nil {
+		return nil
+	}
+	sum, err := HashFile(path)
+	if err != nil {
+		return err
+	}
+	if old, ok := m.Lookup(path); ok && old.SHA256 == sum && old.DerivedFrom == "" {
+		return nil
+	}
+	return m.Record(Entry{Path: path, URL: url, SHA256: sum})
+}
+
+// Derive records path as produced from the file from, inheriting its URL
+// and hash. Nothing is recorded when from is unknown.
+func (m *Manifest) Derive(path, from string) {
+	src, ok := m.Lookup(from)
+	if !ok {
+		return
+	}
+	m.mu.Lock()
+	m.entries[path] = &Entry{Path: path, URL: src.URL, SHA256: src.SHA256, DerivedFrom: from}
+	m.mu.Unlock()
+}
+
+// Lookup returns the entry of a local path
+func (m *Manifest) Lookup(path string) (Entry, bool) {
+	if m == nil {
+		return Entry{}, false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	e, ok := m.entries[path]
+	if !ok {
+		return Entry{}, false
+	}
+	return *e, true
+}
+
+// Save writes the manifest, sorted by path
+func (m *Manifest) Save() error {
+	m.mu.RLock()
+	entries := make([]*Entry, 0, len(m.entries))
+	for _, e := range m.entries {
+		entries = append(entries, e)
+	}
+	m.mu.RUnlock()
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(m.path, data, 0644)
+}
+
+// HashFile returns the hex SHA-256 of a file
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
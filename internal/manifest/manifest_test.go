@@ -0,0 +1,109 @@
+package manifest
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	out := t.TempDir()
+	bundle := filepath.Join(out, "js_files", "app.js")
+	beautified := filepath.Join(out, "js_beautified", "app.js")
+	local := filepath.Join(out, "raw", "local.js")
+	writeFile(t, bundle, "var a=1;")
+	writeFile(t, beautified, "var a = 1;\n")
+	writeFile(t, local, "var b=2;")
+
+	m, err := Load(out)
+	if err != nil || m.Len() != 0 {
+		t.Fatalf("empty manifest: %d entries, %v", m.Len(), err)
+	}
+	fetched := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := m.Record(Entry{
+		Path:      bundle,
+		URL:       "https://example.com/static/app.js?v=3",
+		Status:    200,
+		FetchedAt: &fetched,
+		Headers:   http.Header{"Content-Type": {"application/javascript"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	m.Derive(beautified, bundle)
+	m.Derive(filepath.Join(out, "orphan.js"), filepath.Join(out, "unknown.js")) // Unknown source: not recorded
+	if err := m.Ensure(local, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Len() != 3 {
+		t.Errorf("%d entries, want 3", loaded.Len())
+	}
+	for _, path := range []string{bundle, beautified, local} {
+		want, _ := m.Lookup(path)
+		got, ok := loaded.Lookup(path)
+		if !ok || !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: loaded %+v, want %+v", path, got, want)
+		}
+	}
+
+	// Hashes identify the fetched content, which derived files inherit
+	sum, _ := HashFile(bundle)
+	if e, _ := loaded.Lookup(bundle); e.SHA256 != sum {
+		t.Errorf("bundle hash %s, want %s", e.SHA256, sum)
+	}
+	if e, _ := loaded.Lookup(beautified); e.URL != "https://example.com/static/app.js?v=3" || e.SHA256 != sum || e.DerivedFrom != bundle {
+		t.Errorf("beautified entry %+v", e)
+	}
+}
+
+func TestEnsure(t *testing.T) {
+	out := t.TempDir()
+	path := filepath.Join(out, "app.js")
+	writeFile(t, path, "v1")
+
+	m, _ := Load(out)
+	m.Record(Entry{Path: path, URL: "https://example.com/app.js"})
+
+	// Unchanged content keeps the recorded URL
+	m.Ensure(path, "")
+	if e, _ := m.Lookup(path); e.URL != "https://example.com/app.js" {
+		t.Errorf("unchanged file lost its URL: %+v", e)
+	}
+
+	// Changed content is a different file
+	writeFile(t, path, "v2")
+	m.Ensure(path, "")
+	if e, _ := m.Lookup(path); e.URL != "" {
+		t.Errorf("changed file kept the old URL: %+v", e)
+	}
+}
+
+func TestNilManifest(t *testing.T) {
+	var m *Manifest
+	if err := m.Record(Entry{Path: "x"}); err != nil || m.Len() != 0 {
+		t.Errorf("Record on nil: %v", err)
+	}
+	if _, ok := m.Lookup("x"); ok {
+		t.Error("Lookup on nil found an entry")
+	}
+}
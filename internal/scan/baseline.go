@@ -0,0 +1,156 @@
+package scan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shaniidev/keyana/internal/core"
+)
+
+// baselineVersion is bumped when the fingerprint or file layout changes
+const baselineVersion = 1
+
+// Baseline is a findings export that later runs are compared against
+type Baseline struct {
+	Version  int             `json:"version"`
+	Created  time.Time       `json:"created"`
+	Findings []BaselineEntry `json:"findings"`
+}
+
+// BaselineEntry is one finding of a baseline
+type BaselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	PatternID   string `json:"pattern_id,omitempty"`
+	Detector    string `json:"detector"`
+	Type        string `json:"type"`
+	Severity    string `json:"severity,omitempty"`
+	Score       int    `json:"score"`
+	Value       string `json:"value"`
+	File        string `json:"file"`
+	URL         string `json:"url,omitempty"`
+	Line        int    `json:"line"`
+	Redacted    bool   `json:"redacted,omitempty"` // Value is already redacted
+}
+
+// Fingerprint identifies a finding across runs by its pattern, normalized
+// value and source. The source is the URL when known, otherwise the file's
+// base name, so the fingerprint does not depend on the output directory.
+// Sources unpacked from a source map add their original name.
+// Findings without a pattern ID are keyed by detector and type instead.
+func Fingerprint(sec core.Secret) string {
+	rule := sec.PatternID
+	if rule == "" {
+		rule = sec.Detector + ":" + sec.Type
+	}
+	source := sec.URL
+	if source == "" {
+		source = filepath.Base(sec.File)
+	}
+	if sec.Origin != "" {
+		source += "#" + sec.Origin // One map holds many sources
+	}
+
+	sum := sha256.Sum256([]byte(rule + "\x00" + normalizeValue(sec.Value) + "\x00" + source))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeValue strips whitespace and quoting that detectors disagree on
+func normalizeValue(v string) string {
+	return strings.Trim(strings.TrimSpace(v), "\"'`")
+}
+
+// NewBaseline builds a baseline from the findings of a run
+func NewBaseline(secrets []core.Secret) *Baseline {
+	b := &Baseline{Version: baselineVersion, Created: time.Now().UTC()}
+	seen := make(map[string]bool)
+	for _, sec := range secrets {
+		fp := Fingerprint(sec)
+		if seen[fp] {
+			continue
+		}
+		seen[fp] = true
+		b.Findings = append(b.Findings, BaselineEntry{
+			Fingerprint: fp,
+			PatternID:   sec.PatternID,
+			Detector:    sec.Detector,
+			Type:        sec.Type,
+			Severity:    sec.Severity,
+			Score:       sec.Score,
+			Value:       sec.Value,
+			File:        sec.File,
+			URL:         sec.URL,
+			Line:        sec.Line,
+		})
+	}
+	return b
+}
+
+// LoadBaseline reads a baseline written by WriteBaseline
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parse baseline %s: %w", path, err)
+	}
+	if b.Version != baselineVersion {
+		return nil, fmt.Errorf("baseline %s has version %d, expected %d", path, b.Version, baselineVersion)
+	}
+	return &b, nil
+}
+
+// WriteBaseline saves the findings of a run as a baseline. Values are
+// redacted per mode; fingerprints are always taken from the full value.
+func WriteBaseline(path string, secrets []core.Secret, mode RedactMode) error {
+	b := NewBaseline(secrets)
+	if mode != RedactNone {
+		for i := range b.Findings {
+			b.Findings[i].Value = mode.Value(b.Findings[i].Value)
+			b.Findings[i].Redacted = true
+		}
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Diff splits the current findings into those missing from the baseline and
+// returns the baseline entries that no current finding matches
+func (b *Baseline) Diff(current []core.Secret) (added []core.Secret, resolved []BaselineEntry) {
+	known := make(map[string]bool, len(b.Findings))
+	for _, e := range b.Findings {
+		known[e.Fingerprint] = true
+	}
+
+	present := make(map[string]bool, len(current))
+	for _, sec := range current {
+		fp := Fingerprint(sec)
+		present[fp] = true
+		if !known[fp] {
+			added = append(added, sec)
+		}
+	}
+
+	for _, e := range b.Findings {
+		if !present[e.Fingerprint] {
+			resolved = append(resolved, e)
+		}
+	}
+	return added, resolved
+}
@@ -0,0 +1,286 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"sort"
+
+	"github.com/shaniidev/keyana/internal/core"
+	"github.com/shaniidev/keyana/internal/jslex"
+)
+
+// maxFoldPrefix is how much source before a folded expression (e.g.
+// `apiKey = `) is kept so keyword-anchored patterns still match
+const maxFoldPrefix = 64
+
+// foldedString is a constant string expression reconstructed from its parts
+type foldedString struct {
+	Start, End int // Span of the expression in the source
+	Prefix     []byte
+	Value      []byte
+}
+
+// binding is a variable known to hold a constant string
+type binding struct {
+	value []byte
+	depth int
+}
+
+// foldConcatenations folds `"a" + "b"`, template literals and references to
+// variables initialized with constant strings into the strings they build.
+// Only expressions built from at least two parts are returned; single
+// literals are already visible to the plain scan.
+func foldConcatenations(content []byte) []foldedString {
+	toks := significantTokens(content)
+	text := func(i int) []byte { return content[toks[i].Start:toks[i].End] }
+	isPunct := func(i int, p string) bool {
+		return i >= 0 && i < len(toks) && toks[i].Kind == jslex.Punct && string(text(i)) == p
+	}
+
+	vars := make(map[string]binding)
+	var scopes [][]string // Names declared at each brace depth
+	declare := func(name string, value []byte) {
+		vars[name] = binding{value: value, depth: len(scopes)}
+		if len(scopes) > 0 {
+			scopes[len(scopes)-1] = append(scopes[len(scopes)-1], name)
+		}
+	}
+
+	// operand returns the constant value of the operand at i, or false if it
+	// is not a constant string (or is used as an object, e.g. "x".length)
+	operand := func(i int) ([]byte, bool) {
+		if i >= len(toks) {
+			return nil, false
+		}
+		if i+1 < len(toks) && toks[i+1].Kind == jslex.Punct {
+			switch string(text(i + 1)) {
+			case ".", "?.", "[", "(", "*", "/", "%", "**":
+				return nil, false
+			}
+		}
+		switch toks[i].Kind {
+		case jslex.String:
+			return jslex.Unquote(text(i))
+		case jslex.Template:
+			return foldTemplate(text(i), vars)
+		case jslex.Ident:
+			if isPunct(i-1, ".") || isPunct(i-1, "?.") {
+				return nil, false // Property, not a variable
+			}
+			if b, ok := vars[string(text(i))]; ok {
+				return b.value, true
+			}
+		}
+		return nil, false
+	}
+
+	var out []foldedString
+	for i := 0; i < len(toks); i++ {
+		switch {
+		case isPunct(i, "{"):
+			scopes = append(scopes, nil)
+			continue
+		case isPunct(i, "}"):
+			if len(scopes) > 0 {
+				for _, name := range scopes[len(scopes)-1] {
+					if b, ok := vars[name]; ok && b.depth == len(scopes) {
+						delete(vars, name)
+					}
+				}
+				scopes = scopes[:len(scopes)-1]
+			}
+			continue
+		case toks[i].Kind == jslex.Ident && isAssignOp(toks, content, i+1) && !isPunct(i-1, "."):
+			// Reassigned outside a declaration: no longer constant
+			if !isDeclaration(toks, content, i) {
+				delete(vars, string(text(i)))
+			}
+		}
+
+		// A chain must not be the right operand of a tighter operator
+		if isPunct(i-1, "-") || isPunct(i-1, "*") || isPunct(i-1, "/") || isPunct(i-1, "%") || isPunct(i-1, "**") {
+			continue
+		}
+		first, ok := operand(i)
+		if !ok {
+			continue
+		}
+
+		value := append([]byte(nil), first...)
+		parts, j := 1, i
+		for isPunct(j+1, "+") {
+			v, ok := operand(j + 2)
+			if !ok {
+				break
+			}
+			value = append(value, v...)
+			parts++
+			j += 2
+		}
+
+		if isDeclaration(toks, content, i-2) && chainEnds(toks, content, j+1) {
+			declare(string(text(i-2)), value)
+		}
+		// A template with substitutions is a reconstruction on its own
+		if parts > 1 || toks[i].Kind == jslex.Template && bytes.Contains(text(i), []byte("${")) {
+			start := toks[i].Start
+			out = append(out, foldedString{
+				Start:  start,
+				End:    toks[j].End,
+				Prefix: foldPrefix(content, start),
+				Value:  value,
+			})
+		}
+		i = j
+	}
+	return out
+}
+
+// significantTokens returns the tokens of content without comments
+func significantTokens(content []byte) []jslex.Token {
+	lex := jslex.New(content)
+	var toks []jslex.Token
+	for {
+		tok := lex.Next()
+		if tok.Kind == jslex.EOF {
+			return toks
+		}
+		if tok.Kind != jslex.Comment {
+			toks = append(toks, tok)
+		}
+	}
+}
+
+// foldTemplate returns the value of a template literal whose substitutions
+// are all known variables
+func foldTemplate(lit []byte, vars map[string]binding) ([]byte, bool) {
+	body := lit[1 : len(lit)-1]
+	var out []byte
+	for {
+		k := bytes.Index(body, []byte("${"))
+		if k < 0 {
+			rest, ok := jslex.Unescape(body)
+			return append(out, rest...), ok
+		}
+		head, ok := jslex.Unescape(body[:k])
+		if !ok {
+			return nil, false
+		}
+		out = append(out, head...)
+
+		end := bytes.IndexByte(body[k:], '}')
+		if end < 0 {
+			return nil, false
+		}
+		b, ok := vars[string(bytes.TrimSpace(body[k+2:k+end]))]
+		if !ok {
+			return nil, false
+		}
+		out = append(out, b.value...)
+		body = body[k+end+1:]
+	}
+}
+
+// isDeclaration reports whether toks[i] is the name in `const|let|var name =`
+func isDeclaration(toks []jslex.Token, content []byte, i int) bool {
+	if i < 1 || i+1 >= len(toks) || toks[i].Kind != jslex.Ident {
+		return false
+	}
+	if string(content[toks[i+1].Start:toks[i+1].End]) != "=" {
+		return false
+	}
+	// `var a = "x", b = "y"`: walking back over earlier declarators is not
+	// worth it; a comma before the name is accepted as a declaration list
+	switch string(content[toks[i-1].Start:toks[i-1].End]) {
+	case "const", "let", "var":
+		return true
+	case ",":
+		return i >= 3 && toks[i-2].Kind != jslex.Punct
+	}
+	return false
+}
+
+// isAssignOp reports whether toks[i] is an assignment operator
+func isAssignOp(toks []jslex.Token, content []byte, i int) bool {
+	if i >= len(toks) || toks[i].Kind != jslex.Punct {
+		return false
+	}
+	switch string(content[toks[i].Start:toks[i].End]) {
+	case "=", "+=", "-=", "||=", "&&=", "??=":
+		return true
+	}
+	return false
+}
+
+// chainEnds reports whether an initializer is complete before toks[i]
+func chainEnds(toks []jslex.Token, content []byte, i int) bool {
+	if i >= len(toks) || toks[i].NewlineBefore {
+		return true
+	}
+	switch string(content[toks[i].Start:toks[i].End]) {
+	case ",", ";", "}", ")":
+		return true
+	}
+	return false
+}
+
+// foldPrefix returns the source on the same line before start, up to the
+// previous statement boundary
+func foldPrefix(content []byte, start int) []byte {
+	from := start - maxFoldPrefix
+	if from < 0 {
+		from = 0
+	}
+	prefix := content[from:start]
+	if k := bytes.LastIndexAny(prefix, "\n;{}"); k >= 0 {
+		prefix = prefix[k+1:]
+	}
+	return bytes.TrimLeft(prefix, " \t")
+}
+
+// scanFolded scans the folded string expressions of content. The folded
+// strings are written one per line into a buffer that is scanned once;
+// findings are mapped back to the span of the original expression.
+func (e *Engine) scanFolded(ctx context.Context, content []byte, filePath string, seen map[string]bool, opts ScanOptions) []core.Secret {
+	folded := foldConcatenations(content)
+	if len(folded) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	valueStarts := make([]int, len(folded)) // Offset of each Value in buf
+	for i, f := range folded {
+		buf.Write(f.Prefix)
+		buf.WriteByte('"')
+		valueStarts[i] = buf.Len()
+		buf.Write(f.Value)
+		buf.WriteString("\"\n")
+	}
+
+	inner := opts
+	inner.FoldStrings = false
+	var found []core.Secret
+	var linePositions []int
+	for _, sec := range e.Scan(ctx, buf.Bytes(), filePath, inner) {
+		// A match may start in the prefix (a keyword before the quote) but
+		// must reach into the folded value; one that lies wholly in the
+		// prefix is a literal the plain scan reports on its own
+		i := sort.SearchInts(valueStarts, sec.EndOffset) - 1
+		if i < 0 || sec.Offset >= valueStarts[i]+len(folded[i].Value) || seen[sec.Value] {
+			continue
+		}
+		seen[sec.Value] = true
+		if linePositions == nil {
+			linePositions = buildLineIndex(content)
+		}
+		if sec.Decoded != "" {
+			sec.Decoded = "concat>" + sec.Decoded
+		} else {
+			sec.Decoded = "concat"
+		}
+		sec.Locations = nil
+		sec.AddLocation(newLocation(linePositions, folded[i].Start, folded[i].End))
+		found = append(found, sec)
+	}
+	return found
+}
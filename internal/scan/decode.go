@@ -0,0 +1,217 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"unicode/utf8"
+
+	"github.com/shaniidev/keyana/internal/core"
+	"github.com/shaniidev/keyana/internal/jslex"
+)
+
+// DefaultDecodeDepth is how many encoding layers are peeled off a literal
+const DefaultDecodeDepth = 2
+
+// minDecodedLen skips payloads too short to hold a credential
+const minDecodedLen = 8
+
+// decodedLiteral is a string literal whose content decoded to readable text
+type decodedLiteral struct {
+	Start, End int // Span of the literal, quotes included
+	Text       []byte
+	Encoding   string
+}
+
+// decodeLiterals finds string literals hidden behind atob()/Buffer.from(),
+// \x and \u escapes, URL encoding or hex, and returns their decoded text
+func decodeLiterals(content []byte) []decodedLiteral {
+	var out []decodedLiteral
+	for i := 0; i < len(content); i++ {
+		q := content[i]
+		if q != '"' && q != '\'' && q != '`' {
+			continue
+		}
+		end := literalEnd(content, i)
+		if end < 0 {
+			continue
+		}
+		body := content[i+1 : end]
+		if text, enc, ok := decodeLiteral(content, i, end, body); ok {
+			out = append(out, decodedLiteral{Start: i, End: end + 1, Text: text, Encoding: enc})
+		}
+		i = end
+	}
+	return out
+}
+
+// literalEnd returns the index of the quote closing the literal opened at
+// start, or -1. Single and double quoted literals end at a newline.
+func literalEnd(content []byte, start int) int {
+	q := content[start]
+	for j := start + 1; j < len(content); j++ {
+		switch content[j] {
+		case '\\':
+			j++
+		case '\n':
+			if q != '`' {
+				return -1
+			}
+		case q:
+			return j
+		}
+	}
+	return -1
+}
+
+func decodeLiteral(content []byte, start, end int, body []byte) ([]byte, string, bool) {
+	if len(body) < minDecodedLen {
+		return nil, "", false
+	}
+
+	if isBase64Call(content, start, end) {
+		if text, ok := decodeBase64(body); ok {
+			return text, "base64", true
+		}
+	}
+	if bytes.Contains(body, []byte(`\x`)) || bytes.Contains(body, []byte(`\u`)) {
+		if text, ok := unescapeJS(body); ok {
+			return text, "escape", true
+		}
+	}
+	if bytes.Count(body, []byte("%")) >= 3 {
+		if text, err := url.PathUnescape(string(body)); err == nil && text != string(body) && readable([]byte(text)) {
+			return []byte(text), "url", true
+		}
+	}
+	if text, ok := decodeHex(body); ok {
+		return text, "hex", true
+	}
+	return nil, "", false
+}
+
+// isBase64Call reports whether the literal is the argument of atob(...) or
+// Buffer.from(..., "base64")
+func isBase64Call(content []byte, start, end int) bool {
+	before := bytes.TrimRight(content[:start], " \t\n")
+	if !bytes.HasSuffix(before, []byte("(")) {
+		return false
+	}
+	before = bytes.TrimRight(before[:len(before)-1], " \t\n")
+	if bytes.HasSuffix(before, []byte("atob")) {
+		return true
+	}
+	if !bytes.HasSuffix(before, []byte("Buffer.from")) {
+		return false
+	}
+	after := content[end+1:]
+	if len(after) > 20 {
+		after = after[:20]
+	}
+	return bytes.Contains(after, []byte("base64"))
+}
+
+// decodePayload decodes a whole payload that is itself encoded. It is only
+// applied to the output of a previous layer, where bare base64 or hex is
+// not just any identifier-like string.
+func decodePayload(text []byte) ([]byte, string, bool) {
+	text = bytes.TrimSpace(text)
+	if len(text) < minDecodedLen {
+		return nil, "", false
+	}
+	if out, ok := decodeHex(text); ok {
+		return out, "hex", true
+	}
+	if out, ok := decodeBase64(text); ok {
+		return out, "base64", true
+	}
+	if bytes.Contains(text, []byte(`\x`)) || bytes.Contains(text, []byte(`\u`)) {
+		if out, ok := unescapeJS(text); ok {
+			return out, "escape", true
+		}
+	}
+	return nil, "", false
+}
+
+func decodeBase64(s []byte) ([]byte, bool) {
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		out, err := enc.DecodeString(string(s))
+		if err == nil {
+			return out, len(out) >= minDecodedLen && readable(out)
+		}
+	}
+	return nil, false
+}
+
+func decodeHex(s []byte) ([]byte, bool) {
+	if len(s) < 2*minDecodedLen || len(s)%2 != 0 {
+		return nil, false
+	}
+	out := make([]byte, hex.DecodedLen(len(s)))
+	if _, err := hex.Decode(out, s); err != nil {
+		return nil, false
+	}
+	return out, readable(out)
+}
+
+// unescapeJS resolves the escape sequences of a JS string literal body
+func unescapeJS(s []byte) ([]byte, bool) {
+	out, ok := jslex.Unescape(s)
+	return out, ok && readable(out)
+}
+
+// readable reports whether decoded bytes look like text rather than binary
+func readable(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	printable := 0
+	for _, c := range b {
+		if c >= 0x20 && c < 0x7f || c == '\n' || c == '\t' || c == '\r' || c >= 0x80 {
+			printable++
+		}
+	}
+	return printable*10 >= len(b)*9
+}
+
+// scanDecoded scans the decoded form of every encoded literal in content.
+// Findings keep the literal's location in content and record the chain of
+// encodings in Decoded, outermost first (e.g. "base64>hex").
+func (e *Engine) scanDecoded(ctx context.Context, content []byte, filePath string, seen map[string]bool, opts ScanOptions) []core.Secret {
+	var found []core.Secret
+	var linePositions []int
+
+	for _, lit := range decodeLiterals(content) {
+		text, chain := lit.Text, lit.Encoding
+		for depth := opts.DecodeDepth; depth > 0; depth-- {
+			inner := opts
+			inner.DecodeDepth = depth - 1
+			for _, sec := range e.Scan(ctx, text, filePath, inner) {
+				if seen[sec.Value] {
+					continue
+				}
+				seen[sec.Value] = true
+				if linePositions == nil {
+					linePositions = buildLineIndex(content)
+				}
+				if sec.Decoded != "" {
+					sec.Decoded = chain + ">" + sec.Decoded
+				} else {
+					sec.Decoded = chain
+				}
+				sec.Locations = nil
+				sec.AddLocation(newLocation(linePositions, lit.Start, lit.End))
+				found = append(found, sec)
+			}
+
+			next, enc, ok := decodePayload(text)
+			if !ok {
+				break
+			}
+			text, chain = next, chain+">"+enc
+		}
+	}
+	return found
+}
@@ -0,0 +1,96 @@
+package scan
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/shaniidev/keyana/internal/core"
+)
+
+const decodeSecret = "AKIAQZ0KX9M2WL4PQRST"
+
+func scanDecodedLiteral(t *testing.T, literal string, depth int) []core.Secret {
+	t.Helper()
+	e := NewEngine([]CompiledPattern{
+		mustCompile(t, PatternTemplate{ID: "aws", Regex: `AKIA[A-Z0-9]{16}`}),
+	})
+	content := "var a = 1;\nvar k = " + literal + ";\n"
+	return e.Scan(context.Background(), []byte(content), "test.js", ScanOptions{DecodeDepth: depth})
+}
+
+func TestDecodedLiterals(t *testing.T) {
+	// The bytes "?>>" encode to "Pz4+", which differs between the alphabets
+	payload := []byte("?>>" + decodeSecret)
+	std := base64.StdEncoding.EncodeToString(payload)
+	urlSafe := base64.URLEncoding.EncodeToString(payload)
+	if std == urlSafe {
+		t.Fatalf("payload %q encodes the same in both alphabets", payload)
+	}
+
+	tests := []struct {
+		name    string
+		literal string
+		decoded string
+	}{
+		{"atob", `atob("` + std + `")`, "base64"},
+		{"atob url alphabet", `atob('` + urlSafe + `')`, "base64"},
+		{"atob unpadded", `atob("` + base64.RawStdEncoding.EncodeToString(payload) + `")`, "base64"},
+		{"Buffer.from", `Buffer.from("` + std + `", "base64").toString()`, "base64"},
+		{"hex", `"` + hex.EncodeToString([]byte(decodeSecret)) + `"`, "hex"},
+		{"url encoding", `"%41%4B%49%41` + decodeSecret[4:] + `"`, "url"},
+		{"x escapes", `"\x41\x4b\x49\x41` + decodeSecret[4:] + `"`, "escape"},
+		{"u escapes", "`\\u0041\\u{4B}IA" + decodeSecret[4:] + "`", "escape"},
+		{"two layers", `atob("` + base64.StdEncoding.EncodeToString([]byte(hex.EncodeToString([]byte(decodeSecret)))) + `")`, "base64>hex"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secrets := scanDecodedLiteral(t, tt.literal, DefaultDecodeDepth)
+			if len(secrets) != 1 {
+				t.Fatalf("%d findings, want 1", len(secrets))
+			}
+			s := secrets[0]
+			if s.Value != decodeSecret || s.Decoded != tt.decoded {
+				t.Errorf("value %q decoded %q, want %q via %q", s.Value, s.Decoded, decodeSecret, tt.decoded)
+			}
+
+			// The finding points at the encoded literal, not into the decoded text
+			start := strings.IndexAny(tt.literal, "\"'`")
+			end := strings.LastIndexAny(tt.literal, "\"'`") + 1
+			if tt.name == "Buffer.from" {
+				end = strings.Index(tt.literal, `",`) + 1
+			}
+			wantOffset := len("var a = 1;\nvar k = ") + start
+			loc := s.Locations[0]
+			if loc.Line != 2 || loc.Offset != wantOffset || loc.EndOffset != wantOffset+end-start {
+				t.Errorf("location line %d offset %d-%d, want line 2 offset %d-%d", loc.Line, loc.Offset, loc.EndOffset, wantOffset, wantOffset+end-start)
+			}
+		})
+	}
+}
+
+func TestDecodeDepth(t *testing.T) {
+	inner := base64.StdEncoding.EncodeToString([]byte("key: " + decodeSecret))
+	tests := []struct {
+		name    string
+		literal string
+		depth   int
+		want    int // Findings
+	}{
+		{"disabled", `atob("` + base64.StdEncoding.EncodeToString([]byte(decodeSecret)) + `")`, 0, 0},
+		{"one layer at depth 1", `atob("` + base64.StdEncoding.EncodeToString([]byte(decodeSecret)) + `")`, 1, 1},
+		{"two layers at depth 1", `atob("` + base64.StdEncoding.EncodeToString([]byte(inner)) + `")`, 1, 0},
+		{"two layers at depth 2", `atob("` + base64.StdEncoding.EncodeToString([]byte(inner)) + `")`, 2, 1},
+		{"bare base64 is not decoded", `"` + base64.StdEncoding.EncodeToString([]byte(decodeSecret)) + `"`, 2, 0},
+		{"binary is not text", `"` + hex.EncodeToString([]byte("\x00\x01\x02\x03\x04\x05\x06\x07"+decodeSecret)) + `"`, 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(scanDecodedLiteral(t, tt.literal, tt.depth)); got != tt.want {
+				t.Errorf("%d findings, want %d", got, tt.want)
+			}
+		})
+	}
+}
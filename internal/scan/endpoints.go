@@ -14,11 +14,13 @@ import (
 
 	"github.com/shaniidev/keyana/internal/config"
 	"github.com/shaniidev/keyana/internal/core"
+	"github.com/shaniidev/keyana/internal/manifest"
 	"github.com/shaniidev/keyana/internal/ui"
 )
 
 type EndpointScanner struct {
-	Config *config.Config
+	Config   *config.Config
+	Manifest *manifest.Manifest // Supplies the URL and hash of scanned files, if set
 }
 
 func NewEndpointScanner(cfg *config.Config) *EndpointScanner {
@@ -105,6 +107,11 @@ func (e *EndpointScanner) Run(files []string) []core.Endpoint {
 
 	fmt.Printf("[+] Endpoints scan log saved: %s\n", logPath)
 
+	for i := range endpoints {
+		if entry, ok := e.Manifest.Lookup(endpoints[i].File); ok {
+			endpoints[i].URL, endpoints[i].SHA256 = entry.URL, entry.SHA256
+		}
+	}
 	return endpoints
 }
 
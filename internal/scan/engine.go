@@ -2,147 +2,339 @@ package scan
 
 import (
 	"bytes"
-	"fmt"
-	"sync"
+	"context"
+	"sort"
+	"time"
 
 	"github.com/shaniidev/keyana/internal/core"
 	"github.com/shaniidev/keyana/internal/utils"
 
 	"github.com/cloudflare/ahocorasick"
+	"github.com/coregx/coregex"
 )
 
 // Engine is the high-performance scanning engine
 type Engine struct {
-	Matcher          *ahocorasick.Matcher
-	KeywordIndexMap  map[int][]int // Maps keyword_index (from AC) -> list of pattern indices (in AllPatterns)
-	FallbackPatterns []int         // Indices of patterns with no keywords (must always run)
-	AllPatterns      []CompiledPattern
-	IsReady          bool
-	mu               sync.RWMutex
+	Matcher             *ahocorasick.Matcher
+	KeywordIndexMap     map[int][]int        // Maps keyword_index (from AC) -> list of pattern indices (in AllPatterns)
+	FoldMatcher         *ahocorasick.Matcher // Lowercased keywords of case-insensitive patterns, run on lowercased content
+	FoldKeywordIndexMap map[int][]int
+	FallbackPatterns    []int // Indices of patterns with no keywords (must always run)
+	AllPatterns         []CompiledPattern
+	Stats               EngineStats
+	IsReady             bool
+	regexes             []regexSource // Per-pattern regex clones, indexed like AllPatterns
 }
 
-var GlobalEngine = &Engine{
-	KeywordIndexMap: make(map[int][]int),
+// EngineStats describes how patterns were distributed by NewEngine
+type EngineStats struct {
+	Indexed      int // Patterns reachable through a prefilter keyword
+	FoldIndexed  int // Of those, patterns indexed case-insensitively
+	Promoted     int // Patterns a single-literal extraction would have left as fallbacks
+	Fallbacks    int
+	Keywords     int
+	FoldKeywords int
 }
 
-// BuildEngine compiles the Aho-Corasick matcher from the provided patterns
-func BuildEngine(patterns []CompiledPattern) {
-	GlobalEngine.mu.Lock()
-	defer GlobalEngine.mu.Unlock()
+// keywordTable dedups prefilter keywords and maps them to patterns
+type keywordTable struct {
+	keywords []string
+	index    map[string]int // Keyword -> index in keywords
+	patterns map[int][]int  // Keyword index -> pattern indices
+}
+
+func newKeywordTable() *keywordTable {
+	return &keywordTable{index: make(map[string]int), patterns: make(map[int][]int)}
+}
+
+func (t *keywordTable) add(kw string, patternIdx int) {
+	idx, exists := t.index[kw]
+	if !exists {
+		idx = len(t.keywords)
+		t.keywords = append(t.keywords, kw)
+		t.index[kw] = idx
+	}
+	t.patterns[idx] = append(t.patterns[idx], patternIdx)
+}
 
-	GlobalEngine.AllPatterns = patterns
-	GlobalEngine.KeywordIndexMap = make(map[int][]int)
-	GlobalEngine.FallbackPatterns = []int{}
+// NewEngine compiles the Aho-Corasick prefilter for the provided patterns.
+// The returned engine is immutable and safe for concurrent use.
+func NewEngine(patterns []CompiledPattern) *Engine {
+	e := &Engine{
+		AllPatterns:      patterns,
+		FallbackPatterns: []int{},
+		regexes:          make([]regexSource, len(patterns)),
+	}
 
-	var keywords []string
-	// Temporary map to dedup keywords and track their index in the 'keywords' slice
-	// Keyword string -> Index in 'keywords' slice
-	keywordToSliceIdx := make(map[string]int)
+	exact := newKeywordTable()
+	folded := newKeywordTable()
+	var stats EngineStats
 
 	for i, p := range patterns {
-		kw := ExtractKeyword(p.RegexString)
-
-		if IsValidKeyword(kw) {
-			sliceIdx, exists := keywordToSliceIdx[kw]
-			if !exists {
-				sliceIdx = len(keywords)
-				keywords = append(keywords, kw)
-				keywordToSliceIdx[kw] = sliceIdx
-			}
-			GlobalEngine.KeywordIndexMap[sliceIdx] = append(GlobalEngine.KeywordIndexMap[sliceIdx], i)
-		} else {
-			GlobalEngine.FallbackPatterns = append(GlobalEngine.FallbackPatterns, i)
+		e.regexes[i] = newRegexPool(p.Regex)
+
+		set, fold := ExtractKeywords(p.RegexString)
+		if set == nil {
+			e.FallbackPatterns = append(e.FallbackPatterns, i)
+			continue
+		}
+
+		table := exact
+		if fold {
+			table = folded
+			stats.FoldIndexed++
+		}
+		for _, kw := range set {
+			table.add(kw, i)
+		}
+
+		stats.Indexed++
+		if !IsValidKeyword(ExtractKeyword(p.RegexString)) {
+			stats.Promoted++
 		}
 	}
 
 	// Cloudflare NewStringMatcher takes a slice of strings
-	GlobalEngine.Matcher = ahocorasick.NewStringMatcher(keywords)
-	GlobalEngine.IsReady = true
+	e.Matcher = ahocorasick.NewStringMatcher(exact.keywords)
+	e.KeywordIndexMap = exact.patterns
+	e.FoldMatcher = ahocorasick.NewStringMatcher(folded.keywords)
+	e.FoldKeywordIndexMap = folded.patterns
+	e.IsReady = true
 
-	fmt.Printf("[+] Engine optimized: %d patterns indexed via %d unique keywords, %d fallbacks\n",
-		len(patterns)-len(GlobalEngine.FallbackPatterns), len(keywords), len(GlobalEngine.FallbackPatterns))
+	stats.Fallbacks = len(e.FallbackPatterns)
+	stats.Keywords = len(exact.keywords)
+	stats.FoldKeywords = len(folded.keywords)
+	e.Stats = stats
+
+	return e
 }
 
-// ScanContent runs the high-performance scan on a file
-func (e *Engine) ScanContent(content []byte, filePath string, seen map[string]bool, linePositions []int, skipGeneric bool) []core.Secret {
-	e.mu.RLock()
-	defer e.mu.RUnlock()
+// triggered returns the patterns whose prefilter keywords occur in content
+func (e *Engine) triggered(content []byte) map[int]bool {
+	patternsToCheck := make(map[int]bool)
+
+	// MatchThreadSafe returns indices of the keywords found in the content (Match
+	// keeps per-call state on the matcher and races between workers)
+	for _, matchIdx := range e.Matcher.MatchThreadSafe(content) {
+		for _, pIdx := range e.KeywordIndexMap[matchIdx] {
+			patternsToCheck[pIdx] = true
+		}
+	}
 
-	if !e.IsReady {
+	if len(e.FoldKeywordIndexMap) > 0 {
+		lowered := bytes.ToLower(content)
+		for _, matchIdx := range e.FoldMatcher.MatchThreadSafe(lowered) {
+			for _, pIdx := range e.FoldKeywordIndexMap[matchIdx] {
+				patternsToCheck[pIdx] = true
+			}
+		}
+	}
+
+	return patternsToCheck
+}
+
+// DefaultContextWindow is the number of bytes searched on each side of a
+// match for the pattern's context keywords
+const DefaultContextWindow = 100
+
+// ScanOptions controls how patterns are applied to content
+type ScanOptions struct {
+	SkipGeneric   bool             // Skip fallback patterns that have no prefilter keyword
+	ContextWindow int              // Bytes around a match searched for context keywords (<= 0 disables the check)
+	Profiler      *PatternProfiler // Records per-pattern timing when set
+	MinConfidence int              // Drop findings whose Score is below this
+	DecodeDepth   int              // Encoding layers decoded off string literals (0 disables decoding)
+	FoldStrings   bool             // Also scan constant string concatenations folded into one string
+}
+
+// ScanContent runs the high-performance scan on a file. It stops before the
+// next pattern once ctx is done.
+func (e *Engine) ScanContent(ctx context.Context, content []byte, filePath string, seen map[string]bool, linePositions []int, opts ScanOptions) []core.Secret {
+	if e == nil || !e.IsReady {
 		return nil
 	}
 
 	var found []core.Secret
 
 	// 1. Run Fallback Patterns (Sequential) - ONLY if not skipping generic
-	if !skipGeneric {
+	if !opts.SkipGeneric {
 		for _, idx := range e.FallbackPatterns {
-			found = append(found, runSinglePattern(e.AllPatterns[idx], content, filePath, seen, linePositions)...)
+			if ctx.Err() != nil {
+				return found
+			}
+			found = append(found, e.runPattern(idx, content, filePath, seen, linePositions, opts)...)
 		}
 	}
 
 	// 2. Run Pre-Filter (Aho-Corasick) - O(n) scan
-	// Match returns indices of the keywords found in the content
-	matches := e.Matcher.Match(content)
+	// 3. Collect potential patterns to check
+	patternsToCheck := e.triggered(content)
 
-	// 3. Process Hits
-	// Collect potential patterns to check
-	patternsToCheck := make(map[int]bool)
+	// 4. Run Triggered Patterns in load order, so the pattern that claims a
+	// value matched by several is the same on every run
+	order := make([]int, 0, len(patternsToCheck))
+	for pIdx := range patternsToCheck {
+		order = append(order, pIdx)
+	}
+	sort.Ints(order)
+	for _, pIdx := range order {
+		if ctx.Err() != nil {
+			return found
+		}
+		found = append(found, e.runPattern(pIdx, content, filePath, seen, linePositions, opts)...)
+	}
 
-	for _, matchIdx := range matches {
-		if patternIndices, ok := e.KeywordIndexMap[matchIdx]; ok {
-			for _, pIdx := range patternIndices {
-				patternsToCheck[pIdx] = true
-			}
+	return found
+}
+
+// Triggers reports whether the prefilter would schedule the pattern at
+// patternIdx for the given content. Fallback patterns always run.
+func (e *Engine) Triggers(patternIdx int, content []byte) bool {
+	if e == nil || !e.IsReady {
+		return false
+	}
+
+	for _, idx := range e.FallbackPatterns {
+		if idx == patternIdx {
+			return true
 		}
 	}
 
-	// 4. Run Triggered Patterns
-	for pIdx := range patternsToCheck {
-		found = append(found, runSinglePattern(e.AllPatterns[pIdx], content, filePath, seen, linePositions)...)
+	return e.triggered(content)[patternIdx]
+}
+
+// runPattern matches one pattern against content using a regex clone
+// borrowed from the pattern's pool
+func (e *Engine) runPattern(idx int, content []byte, filePath string, seen map[string]bool, linePositions []int, opts ScanOptions) []core.Secret {
+	re := e.regexes[idx].get()
+	defer e.regexes[idx].put(re)
+
+	if opts.Profiler == nil {
+		results, _ := runSinglePattern(e.AllPatterns[idx], re, content, filePath, seen, linePositions, opts)
+		return results
 	}
 
-	return found
+	start := time.Now()
+	results, matches := runSinglePattern(e.AllPatterns[idx], re, content, filePath, seen, linePositions, opts)
+	opts.Profiler.Record(e.AllPatterns[idx].ID, filePath, time.Since(start), len(content), matches)
+	return results
 }
 
-func runSinglePattern(pattern CompiledPattern, content []byte, filePath string, seen map[string]bool, linePositions []int) []core.Secret {
+// runSinglePattern returns the findings of one pattern and the number of raw
+// regex matches they were extracted from
+func runSinglePattern(pattern CompiledPattern, re *coregex.Regexp, content []byte, filePath string, seen map[string]bool, linePositions []int, opts ScanOptions) ([]core.Secret, int) {
 	var results []core.Secret
 
-	// Coregex lazy DFA is not thread-safe, so we must lock per pattern
-	if pattern.Mutex != nil {
-		pattern.Mutex.Lock()
-		defer pattern.Mutex.Unlock()
-	}
+	// FindAllIndex truncates matches of patterns that open with an
+	// alternation, so the span always comes from the submatch search
+	matches := re.FindAllSubmatchIndex(content, -1)
+	byValue := make(map[string]int) // Secret value -> index in results
 
-	matches := pattern.Regex.FindAll(content, -1)
+	for _, loc := range matches {
+		match := content[loc[0]:loc[1]]
 
-	for _, match := range matches {
-		matchStr := string(match)
-		if seen[matchStr] {
+		// Extract the credential from the match
+		secretStart, secretEnd, ok := secretSpan(pattern, loc)
+		if !ok {
+			continue
+		}
+		secret := string(content[secretStart:secretEnd])
+
+		// Further occurrence of a secret this pattern already reported
+		if i, exists := byValue[secret]; exists {
+			results[i].AddLocation(newLocation(linePositions, secretStart, secretEnd))
+			continue
+		}
+		if seen[secret] {
 			continue
 		}
 
-		// Re-implement filters
-		if isFalsePositive(matchStr) {
+		// Template constraints
+		if !withinLength(pattern, len(secret)) {
+			continue
+		}
+		if !hasContext(pattern, content, loc[0], loc[1], opts.ContextWindow) {
+			continue
+		}
+
+		// Re-implement filters. Shape rules judge the whole match: a
+		// declared secret group is only a slice of it. Neither judges the
+		// pattern's own literals (an sk_test_ prefix, a "type" key).
+		if isFalsePositiveValue(maskLiterals(secret, pattern.Literals)) ||
+			isFalsePositiveShape(maskLiterals(string(match), pattern.Literals)) {
 			continue
 		}
 		if pattern.EntropyCheck && pattern.MinEntropy > 0 {
-			if utils.CalculateEntropy(matchStr) < pattern.MinEntropy {
+			if utils.CalculateEntropy(secret) < pattern.MinEntropy {
 				continue
 			}
 		}
 
-		idx := bytes.Index(content, match)
-		lineNum := getLineFromIndex(linePositions, idx)
+		seen[secret] = true
+		found := core.Secret{
+			Type:       pattern.Name,
+			Value:      secret,
+			Context:    string(match),
+			File:       filePath,
+			Detector:   "Template",
+			PatternID:  pattern.ID,
+			Severity:   pattern.Severity,
+			Confidence: pattern.Confidence,
+		}
+		found.AddLocation(newLocation(linePositions, secretStart, secretEnd))
+		ScoreFinding(&found, contextHits(content, loc[0], loc[1], opts.ContextWindow, pattern.ContextKeys))
+		byValue[secret] = len(results)
+		results = append(results, found)
+	}
+	return results, len(matches)
+}
 
-		seen[matchStr] = true
-		results = append(results, core.Secret{
-			Type:     pattern.Name,
-			Value:    matchStr,
-			File:     filePath,
-			Line:     lineNum,
-			Detector: fmt.Sprintf("Template (%s)", pattern.Severity),
-		})
+// secretSpan locates the credential inside a match, given its submatch
+// indices. A declared secret group must have participated in the match;
+// without one the whole match is the credential.
+func secretSpan(pattern CompiledPattern, loc []int) (int, int, bool) {
+	g := pattern.SecretGroup
+	if g == 0 {
+		return loc[0], loc[1], true
 	}
-	return results
+	if 2*g+1 < len(loc) && loc[2*g] >= 0 && loc[2*g+1] > loc[2*g] {
+		return loc[2*g], loc[2*g+1], true
+	}
+	return 0, 0, false
+}
+
+// withinLength checks the min_length / max_length bounds of a pattern
+func withinLength(pattern CompiledPattern, length int) bool {
+	if pattern.MinLength > 0 && length < pattern.MinLength {
+		return false
+	}
+	if pattern.MaxLength > 0 && length > pattern.MaxLength {
+		return false
+	}
+	return true
+}
+
+// hasContext reports whether one of the pattern's context keywords appears
+// in the match or within window bytes on either side of it
+func hasContext(pattern CompiledPattern, content []byte, start, end, window int) bool {
+	if len(pattern.ContextKeys) == 0 || window <= 0 {
+		return true
+	}
+
+	from := start - window
+	if from < 0 {
+		from = 0
+	}
+	to := end + window
+	if to > len(content) {
+		to = len(content)
+	}
+
+	region := bytes.ToLower(content[from:to])
+	for _, kw := range pattern.ContextKeys {
+		if bytes.Contains(region, []byte(kw)) {
+			return true
+		}
+	}
+	return false
 }
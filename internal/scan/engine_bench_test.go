@@ -0,0 +1,219 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/coregx/coregex"
+)
+
+// benchCorpusEnv names a directory of files for BenchmarkEngineScanCorpus
+const benchCorpusEnv = "KEYANA_BENCH_CORPUS"
+
+// Number and approximate size of the bundles BenchmarkEngineScan generates
+const (
+	benchFiles = 200
+	benchSize  = 64 << 10
+)
+
+// lockedRegex is the matching strategy the regex pools replaced: one shared
+// regex per pattern, serialized with a mutex. It is kept here as the
+// baseline of BenchmarkEngineScan.
+type lockedRegex struct {
+	mu sync.Mutex
+	re *coregex.Regexp
+}
+
+func (l *lockedRegex) get() *coregex.Regexp {
+	l.mu.Lock()
+	return l.re
+}
+
+func (l *lockedRegex) put(*coregex.Regexp) {
+	l.mu.Unlock()
+}
+
+// bundleSnippets are the building blocks of generated bundles. Placeholders:
+// $N identifier, $W words, $D number, $H hex digest, $S credential.
+var bundleSnippets = []string{
+	`function $N(e,t){return e.map(function(n){return n.$N+"/"+t})}`,
+	`var $N={apiUrl:"https://api.$N.com/v$D/$N",timeout:$D,retries:$D};`,
+	`e.exports={name:"$N",version:"1.$D.$D",description:"$W"};`,
+	`fetch("/api/$N?id="+e.id).then(function(r){return r.json()}).catch(function(){});`,
+	`var $N="$H";`,
+	`$N.i18n={title:"$W",placeholder:"$W",error:"$W"};`,
+	`if(t.status===$D){throw new Error("$W")}else{n.$N=t.data.$N||[]}`,
+	`for(var i=0;i<e.length;i++){o[e[i].$N]=e[i].value}`,
+	`document.querySelector(".$N-$N").addEventListener("click",function(){$N($D)});`,
+	`__webpack_require__.d(t,{$N:function(){return $N}});`,
+	`var $N="data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk+M9QDwADhgGAWjR9awAAAABJRU5ErkJggg==";`,
+	`config.$N={key:"$S"};`,
+}
+
+var benchWords = []string{"invalid", "request", "please", "try", "again", "account", "settings", "loading", "user", "profile", "payment", "failed"}
+
+func benchCredential(r *rand.Rand) string {
+	const alnum = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	rnd := func(n int) string {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = alnum[r.Intn(len(alnum))]
+		}
+		return string(b)
+	}
+	switch r.Intn(4) {
+	case 0:
+		return "AKIA" + strings.ToUpper(rnd(16))
+	case 1:
+		return "sk_live_" + rnd(24)
+	case 2:
+		return "ghp_" + rnd(36)
+	default:
+		return "postgres://app:" + rnd(12) + "@db.internal:5432/orders"
+	}
+}
+
+// generateBundle returns roughly size bytes of minified-bundle-like code with
+// a credential in about one snippet of every fifty
+func generateBundle(r *rand.Rand, size int) []byte {
+	var sb strings.Builder
+	for sb.Len() < size {
+		snippet := bundleSnippets[r.Intn(len(bundleSnippets)-1)]
+		if r.Intn(50) == 0 {
+			snippet = bundleSnippets[len(bundleSnippets)-1]
+		}
+		for i := 0; i < len(snippet); i++ {
+			if snippet[i] != '$' || i+1 == len(snippet) {
+				sb.WriteByte(snippet[i])
+				continue
+			}
+			i++
+			switch snippet[i] {
+			case 'N':
+				fmt.Fprintf(&sb, "%c%x", 'a'+rune(r.Intn(26)), r.Intn(4096))
+			case 'W':
+				for w := 0; w < 3; w++ {
+					if w > 0 {
+						sb.WriteByte(' ')
+					}
+					sb.WriteString(benchWords[r.Intn(len(benchWords))])
+				}
+			case 'D':
+				fmt.Fprintf(&sb, "%d", r.Intn(1000))
+			case 'H':
+				fmt.Fprintf(&sb, "%016x%016x", r.Uint64(), r.Uint64())
+			case 'S':
+				sb.WriteString(benchCredential(r))
+			}
+		}
+	}
+	return []byte(sb.String())
+}
+
+// generateBenchCorpus returns benchFiles generated bundles and their size
+func generateBenchCorpus() ([][]byte, int64) {
+	r := rand.New(rand.NewSource(1))
+	var files [][]byte
+	var total int64
+	for i := 0; i < benchFiles; i++ {
+		data := generateBundle(r, benchSize)
+		files = append(files, data)
+		total += int64(len(data))
+	}
+	return files, total
+}
+
+// loadBenchCorpus reads every file under dir
+func loadBenchCorpus(b *testing.B, dir string) ([][]byte, int64) {
+	var files [][]byte
+	var total int64
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err == nil {
+			files = append(files, data)
+			total += int64(len(data))
+		}
+		return err
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	if len(files) == 0 {
+		b.Fatalf("%s: empty corpus", dir)
+	}
+	return files, total
+}
+
+// BenchmarkEngineScan measures template scan throughput with one worker per
+// GOMAXPROCS (set with -cpu) over generated minified bundles. Each operation
+// scans one file of the corpus. The mutex variants match every pattern
+// through one shared, locked regex, the pool variants through per-worker
+// clones:
+//
+//	go test ./internal/scan/ -run '^$' -bench EngineScan -cpu 1,2,4,8
+func BenchmarkEngineScan(b *testing.B) {
+	corpus, total := generateBenchCorpus()
+	benchEngineScan(b, corpus, total)
+}
+
+// BenchmarkEngineScanCorpus is BenchmarkEngineScan over the files in the
+// directory named by KEYANA_BENCH_CORPUS. It is skipped when that is unset.
+func BenchmarkEngineScanCorpus(b *testing.B) {
+	dir := os.Getenv(benchCorpusEnv)
+	if dir == "" {
+		b.Skip(benchCorpusEnv + " is not set")
+	}
+	corpus, total := loadBenchCorpus(b, dir)
+	benchEngineScan(b, corpus, total)
+}
+
+func benchEngineScan(b *testing.B, corpus [][]byte, total int64) {
+	patterns, err := LoadPatterns()
+	if err != nil {
+		b.Fatal(err)
+	}
+	opts := ScanOptions{ContextWindow: DefaultContextWindow, DecodeDepth: DefaultDecodeDepth, FoldStrings: true}
+	fast := opts
+	fast.SkipGeneric = true
+
+	pooled := NewEngine(patterns)
+	locked := NewEngine(patterns)
+	for i, p := range patterns {
+		locked.regexes[i] = &lockedRegex{re: p.Regex}
+	}
+
+	for _, eng := range []struct {
+		name   string
+		engine *Engine
+	}{
+		{"mutex", locked},
+		{"pool", pooled},
+	} {
+		for _, mode := range []struct {
+			name string
+			opts ScanOptions
+		}{
+			{"deep", opts},
+			{"fast", fast},
+		} {
+			b.Run(eng.name+"/"+mode.name, func(b *testing.B) {
+				b.SetBytes(total / int64(len(corpus)))
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					for i := 0; pb.Next(); i++ {
+						eng.engine.Scan(context.Background(), corpus[i%len(corpus)], "bench.js", mode.opts)
+					}
+				})
+			})
+		}
+	}
+}
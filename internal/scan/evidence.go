@@ -0,0 +1,105 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shaniidev/keyana/internal/config"
+	"github.com/shaniidev/keyana/internal/core"
+)
+
+// EvidenceDir holds the unredacted findings of every run, readable only by
+// the owner. Reports and logs may be redacted; this store never is.
+const EvidenceDir = "evidence"
+
+// Evidence is the unredacted record of one run
+type Evidence struct {
+	Created time.Time     `json:"created"`
+	Secrets []core.Secret `json:"secrets"`
+}
+
+// SaveEvidence writes the run's findings to the evidence store and returns
+// the file path
+func SaveEvidence(secrets []core.Secret, cfg *config.Config) (string, error) {
+	dir := filepath.Join(cfg.OutputDir, EvidenceDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	ev := Evidence{Created: time.Now().UTC(), Secrets: secrets}
+	data, err := json.MarshalIndent(ev, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, "secrets_"+ev.Created.Format("20060102_150405")+".json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// LoadEvidence reads an evidence file. If path is a directory (an output
+// directory or its evidence/ folder), the most recent file is used.
+func LoadEvidence(path string) (*Evidence, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if info.IsDir() {
+		if path, err = latestEvidence(path); err != nil {
+			return nil, "", err
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	var ev Evidence
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return nil, "", fmt.Errorf("parse evidence %s: %w", path, err)
+	}
+	return &ev, path, nil
+}
+
+func latestEvidence(dir string) (string, error) {
+	if sub := filepath.Join(dir, EvidenceDir); isDir(sub) {
+		dir = sub
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "secrets_*.json"))
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no evidence files in %s", dir)
+	}
+	// Timestamped names sort chronologically
+	sort.Strings(matches)
+	return matches[len(matches)-1], nil
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// ExportEvidence renders an evidence file unredacted as text or json
+func ExportEvidence(ev *Evidence, format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "", "text", "txt":
+		return FormatSecretReport(SecretReport{Secrets: ev.Secrets}), nil
+	case "json":
+		data, err := json.MarshalIndent(ev, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	}
+	return "", fmt.Errorf("unknown export format %q (use text or json)", format)
+}
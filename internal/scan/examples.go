@@ -0,0 +1,68 @@
+package scan
+
+// ExampleResult holds the outcome of running one pattern against its examples
+type ExampleResult struct {
+	PatternID    string
+	MatchTotal   int
+	MatchPassed  int
+	NoMatchTotal int
+	NoMatchPass  int
+	Failures     []string // Human-readable reason for every failed example
+}
+
+// Passed reports whether every example behaved as declared
+func (r ExampleResult) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// CheckPatternExamples runs each pattern that declares examples against them.
+// A match example passes when the Aho-Corasick prefilter would schedule the
+// pattern and the pattern reports a finding for it once its length, context,
+// entropy and false positive checks have run; a no_match example passes when
+// the regex does not match. The engine must have been built from patterns.
+func CheckPatternExamples(engine *Engine, patterns []CompiledPattern) []ExampleResult {
+	var results []ExampleResult
+
+	for i, p := range patterns {
+		if len(p.Examples.Match) == 0 && len(p.Examples.NoMatch) == 0 {
+			continue
+		}
+
+		res := ExampleResult{
+			PatternID:    p.ID,
+			MatchTotal:   len(p.Examples.Match),
+			NoMatchTotal: len(p.Examples.NoMatch),
+		}
+
+		for _, ex := range p.Examples.Match {
+			content := []byte(ex)
+			// Match misses some leading alternations; the submatch search does not
+			if p.Regex.FindSubmatchIndex(content) == nil {
+				res.Failures = append(res.Failures, "regex missed match example: "+ex)
+				continue
+			}
+			if !engine.Triggers(i, content) {
+				res.Failures = append(res.Failures, "prefilter skipped match example: "+ex)
+				continue
+			}
+			opts := ScanOptions{ContextWindow: DefaultContextWindow}
+			if len(engine.runPattern(i, content, "example", make(map[string]bool), buildLineIndex(content), opts)) == 0 {
+				res.Failures = append(res.Failures, "filters dropped match example: "+ex)
+				continue
+			}
+			res.MatchPassed++
+		}
+
+		for _, ex := range p.Examples.NoMatch {
+			if p.Regex.FindSubmatchIndex([]byte(ex)) != nil {
+				res.Failures = append(res.Failures, "regex matched no_match example: "+ex)
+				continue
+			}
+			res.NoMatchPass++
+		}
+
+		results = append(results, res)
+	}
+
+	return results
+}
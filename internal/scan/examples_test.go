@@ -0,0 +1,33 @@
+package scan
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuiltinExamples(t *testing.T) {
+	patterns, err := LoadPatterns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range CheckPatternExamples(NewEngine(patterns), patterns) {
+		for _, f := range r.Failures {
+			t.Errorf("%s: %s", r.PatternID, f)
+		}
+	}
+
+	for _, p := range patterns {
+		// High and critical patterns must show what they catch
+		if (p.Severity == "high" || p.Severity == "critical") && len(p.Examples.Match) == 0 {
+			t.Errorf("%s: %s pattern has no match examples", p.ID, p.Severity)
+		}
+		// and what they reject: a cut-off match only exercises its length
+		for _, nm := range p.Examples.NoMatch {
+			for _, m := range p.Examples.Match {
+				if strings.HasPrefix(m, nm) {
+					t.Errorf("%s: no_match example %q is a truncated match example", p.ID, nm)
+				}
+			}
+		}
+	}
+}
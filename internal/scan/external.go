@@ -0,0 +1,430 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaniidev/keyana/internal/config"
+	"github.com/shaniidev/keyana/internal/core"
+	"github.com/shaniidev/keyana/internal/ui"
+)
+
+// ============================================================================
+// EXTERNAL SCANNER INTEGRATIONS
+// ============================================================================
+
+// ExternalScanner is a secret scanning tool run as a separate process
+type ExternalScanner interface {
+	// Name is the tool's binary and the Detector of its findings
+	Name() string
+	// Available reports whether the tool can be run
+	Available() bool
+	// Run executes the tool over the staged files, writing the commands
+	// and any diagnostics to log, and returns what it printed
+	Run(stage *Stage, log io.Writer) ([]Output, error)
+	// Parse turns one output of Run into findings. Paths are translated
+	// back to the original files with stage.Original.
+	Parse(out Output, stage *Stage) ([]core.Secret, error)
+}
+
+// Output is the raw result of an external scanner
+type Output struct {
+	File string // Staged file the output is about ("" = the whole stage)
+	Data []byte
+}
+
+// ExternalScanners returns the external scanners enabled in cfg
+func ExternalScanners(cfg *config.Config) []ExternalScanner {
+	var list []ExternalScanner
+	if cfg.Gitleaks {
+		list = append(list, Gitleaks{})
+	}
+	if cfg.JSLuice {
+		list = append(list, JSLuice{Concurrency: cfg.Concurrency})
+	}
+	if cfg.TruffleHog {
+		list = append(list, TruffleHog{Verify: cfg.Verify})
+	}
+	return list
+}
+
+// onPath reports whether a binary is in PATH
+func onPath(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// Stage is a directory holding exactly the files selected for scanning, so
+// that tools which scan a whole directory see those files and nothing else.
+// Files are hard-linked when possible and copied otherwise.
+type Stage struct {
+	Dir   string
+	Files []string          // Staged paths, in the order of the originals
+	orig  map[string]string // Staged base name -> original path
+}
+
+// NewStage stages files in a new directory inside parent
+func NewStage(parent string, files []string) (*Stage, error) {
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(parent, "scan-stage-")
+	if err != nil {
+		return nil, err
+	}
+	st := &Stage{Dir: dir, orig: make(map[string]string, len(files))}
+	for i, f := range files {
+		// Bundles and unpacked sources often share base names
+		name := fmt.Sprintf("%05d_%s", i, filepath.Base(f))
+		path := filepath.Join(dir, name)
+		if err := linkOrCopy(f, path); err != nil {
+			st.Remove()
+			return nil, err
+		}
+		st.Files = append(st.Files, path)
+		st.orig[name] = f
+	}
+	return st, nil
+}
+
+// Original returns the file a staged path stands for. Paths outside the
+// stage are returned unchanged.
+func (st *Stage) Original(path string) string {
+	if f, ok := st.orig[filepath.Base(path)]; ok {
+		return f
+	}
+	return path
+}
+
+// Remove deletes the stage directory
+func (st *Stage) Remove() error {
+	return os.RemoveAll(st.Dir)
+}
+
+func linkOrCopy(src, dst string) error {
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// availableScanners returns the scanners that can run, logging the others
+func availableScanners(list []ExternalScanner, logFile io.Writer) []ExternalScanner {
+	var avail []ExternalScanner
+	for _, ext := range list {
+		if ext.Available() {
+			avail = append(avail, ext)
+			continue
+		}
+		name := ext.Name()
+		ui.Warning("%s not found in PATH, skipping", name)
+		fmt.Fprintf(logFile, "--- %s SCANNER ---\n", strings.ToUpper(name))
+		fmt.Fprintf(logFile, "Status: skipped (%s not found in PATH)\n\n", name)
+	}
+	return avail
+}
+
+// runExternal runs one available external scanner over the stage and
+// returns its scored findings in the original files
+func (s *SecretScanner) runExternal(ext ExternalScanner, stage *Stage, logFile io.Writer) []core.Secret {
+	name := ext.Name()
+	ui.Info("Starting %s...", name)
+	fmt.Fprintf(logFile, "--- %s SCANNER ---\n", strings.ToUpper(name))
+	if stage == nil {
+		fmt.Fprintf(logFile, "Status: skipped (files could not be staged)\n\n")
+		return nil
+	}
+
+	startTime := time.Now()
+	outputs, err := ext.Run(stage, logFile)
+	if err != nil {
+		fmt.Fprintf(logFile, "Error executing: %v\n", err)
+	}
+
+	var found []core.Secret
+	for _, out := range outputs {
+		r, err := ext.Parse(out, stage)
+		if err != nil {
+			fmt.Fprintf(logFile, "Error parsing output: %v\n", err)
+			continue
+		}
+		found = append(found, r...)
+	}
+	for i := range found {
+		ScoreFinding(&found[i], 0)
+	}
+
+	fmt.Fprintf(logFile, "Completed in: %s\n", time.Since(startTime))
+	fmt.Fprintf(logFile, "Findings: %d\n", len(found))
+	if len(found) == 0 && err == nil {
+		fmt.Fprintf(logFile, "Status: No findings (scanner executed successfully)\n")
+	}
+	fmt.Fprintf(logFile, "\n")
+	ui.Success("%s finished", name)
+	return found
+}
+
+// Gitleaks runs `gitleaks detect` over the stage directory
+type Gitleaks struct{}
+
+type GitleaksResult struct {
+	Description string `json:"Description"`
+	Secret      string `json:"Secret"`
+	File        string `json:"File"`
+	Match       string `json:"Match"`
+	StartLine   int    `json:"StartLine"`
+	StartColumn int    `json:"StartColumn"`
+}
+
+func (Gitleaks) Name() string    { return "gitleaks" }
+func (Gitleaks) Available() bool { return onPath("gitleaks") }
+
+func (Gitleaks) Run(stage *Stage, log io.Writer) ([]Output, error) {
+	// The report must not land in the scanned directory
+	report, err := os.CreateTemp(filepath.Dir(stage.Dir), "gitleaks-*.json")
+	if err != nil {
+		return nil, err
+	}
+	report.Close()
+	defer os.Remove(report.Name())
+
+	args := []string{"detect", "--source", stage.Dir, "--no-git", "--report-path", report.Name(), "--exit-code", "0"}
+	fmt.Fprintf(log, "Command: gitleaks %s\n", strings.Join(args, " "))
+
+	cmd := exec.Command("gitleaks", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if stderr.Len() > 0 {
+		fmt.Fprintf(log, "Stderr: %s\n", stderr.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(report.Name())
+	if err != nil {
+		return nil, err
+	}
+	return []Output{{Data: data}}, nil
+}
+
+func (Gitleaks) Parse(out Output, stage *Stage) ([]core.Secret, error) {
+	var glRes []GitleaksResult
+	if err := json.Unmarshal(out.Data, &glRes); err != nil {
+		return nil, err
+	}
+
+	lines := make(map[string][]int) // Staged file -> line index
+	contents := make(map[string][]byte)
+	var results []core.Secret
+	for _, r := range glRes {
+		sec := core.Secret{
+			Type:     fmt.Sprintf("Gitleaks: %s", r.Description),
+			Value:    r.Secret,
+			File:     stage.Original(r.File),
+			Detector: "gitleaks",
+		}
+		if _, ok := contents[r.File]; !ok {
+			data, _ := os.ReadFile(r.File)
+			contents[r.File], lines[r.File] = data, buildLineIndex(data)
+		}
+		sec.AddLocation(gitleaksLocation(r, contents[r.File], lines[r.File]))
+		results = append(results, sec)
+	}
+	return results, nil
+}
+
+// gitleaksLocation places the secret of a gitleaks result the way template
+// findings are placed. Gitleaks reports where its whole rule matched, so the
+// secret is looked up from that column on; without the file content only
+// the reported line and column are known.
+func gitleaksLocation(r GitleaksResult, content []byte, linePositions []int) core.Location {
+	loc := core.Location{Line: r.StartLine, Column: r.StartColumn}
+	if r.StartLine < 1 || r.StartLine > len(linePositions) || r.Secret == "" {
+		return loc
+	}
+
+	lineStart := linePositions[r.StartLine-1]
+	// Columns are 1-based; allow for versions that report them 0-based
+	from := lineStart + max(r.StartColumn-2, 0)
+	i := bytes.Index(content[min(from, len(content)):], []byte(r.Secret))
+	if i < 0 {
+		if i = bytes.Index(content[lineStart:], []byte(r.Secret)); i < 0 {
+			return loc
+		}
+		from = lineStart
+	}
+	return newLocation(linePositions, from+i, from+i+len(r.Secret))
+}
+
+// JSLuice runs `jsluice secrets` on each staged file
+type JSLuice struct {
+	Concurrency int
+}
+
+func (JSLuice) Name() string    { return "jsluice" }
+func (JSLuice) Available() bool { return onPath("jsluice") }
+
+func (j JSLuice) Run(stage *Stage, log io.Writer) ([]Output, error) {
+	fmt.Fprintf(log, "Command: jsluice secrets <file>\n")
+	bar := ui.NewProgressBar(len(stage.Files), "JSLuice")
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(j.Concurrency, 1))
+	var outputs []Output
+	failed := 0
+
+	for _, f := range stage.Files {
+		wg.Add(1)
+		go func(filePath string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			defer bar.Increment()
+
+			cmd := exec.Command("jsluice", "secrets", filePath)
+			var stdout, stderr bytes.Buffer
+			cmd.Stdout = &stdout
+			cmd.Stderr = &stderr
+			err := cmd.Run()
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				fmt.Fprintf(log, "[ERROR] %s - %v\n", filepath.Base(stage.Original(filePath)), err)
+				if stderr.Len() > 0 {
+					fmt.Fprintf(log, "  Stderr: %s\n", strings.TrimSpace(stderr.String()))
+				}
+				return
+			}
+			outputs = append(outputs, Output{File: filePath, Data: stdout.Bytes()})
+		}(f)
+	}
+	wg.Wait()
+
+	fmt.Fprintf(log, "Files scanned: %d\n", len(stage.Files))
+	fmt.Fprintf(log, "Files with errors: %d\n", failed)
+	return outputs, nil
+}
+
+func (JSLuice) Parse(out Output, stage *Stage) ([]core.Secret, error) {
+	type JSLuiceOut struct {
+		Kind string `json:"kind"`
+		Data string `json:"data"`
+		Line int    `json:"line"`
+	}
+
+	var secrets []core.Secret
+	scanner := bufio.NewScanner(bytes.NewReader(out.Data))
+	for scanner.Scan() {
+		var jso JSLuiceOut
+		if err := json.Unmarshal(scanner.Bytes(), &jso); err == nil {
+			secrets = append(secrets, core.Secret{
+				Type:     fmt.Sprintf("JSLuice: %s", jso.Kind),
+				Value:    jso.Data,
+				File:     stage.Original(out.File),
+				Line:     jso.Line,
+				Detector: "jsluice",
+			})
+		}
+	}
+	return secrets, scanner.Err()
+}
+
+// TruffleHog runs `trufflehog filesystem` over the stage directory. With
+// Verify set TruffleHog checks its detectors' credentials itself and the
+// findings it confirms are marked verified.
+type TruffleHog struct {
+	Verify bool
+}
+
+func (TruffleHog) Name() string    { return "trufflehog" }
+func (TruffleHog) Available() bool { return onPath("trufflehog") }
+
+func (t TruffleHog) Run(stage *Stage, log io.Writer) ([]Output, error) {
+	args := []string{"filesystem", stage.Dir, "--json", "--no-update"}
+	if !t.Verify {
+		args = append(args, "--no-verification")
+	}
+	fmt.Fprintf(log, "Command: trufflehog %s\n", strings.Join(args, " "))
+
+	cmd := exec.Command("trufflehog", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if stderr.Len() > 0 {
+		fmt.Fprintf(log, "Stderr: %s\n", stderr.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []Output{{Data: stdout.Bytes()}}, nil
+}
+
+func (TruffleHog) Parse(out Output, stage *Stage) ([]core.Secret, error) {
+	type TruffleHogResult struct {
+		DetectorName      string `json:"DetectorName"`
+		Raw               string `json:"Raw"`
+		Verified          bool   `json:"Verified"`
+		VerificationError string `json:"VerificationError"`
+		SourceMetadata    struct {
+			Data struct {
+				Filesystem struct {
+					File string `json:"file"`
+					Line int64  `json:"line"`
+				} `json:"Filesystem"`
+			} `json:"Data"`
+		} `json:"SourceMetadata"`
+	}
+
+	var secrets []core.Secret
+	scanner := bufio.NewScanner(bytes.NewReader(out.Data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var result TruffleHogResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err == nil {
+			sec := core.Secret{
+				Type:     fmt.Sprintf("TruffleHog: %s", result.DetectorName),
+				Value:    result.Raw,
+				File:     stage.Original(result.SourceMetadata.Data.Filesystem.File),
+				Line:     int(result.SourceMetadata.Data.Filesystem.Line),
+				Detector: "trufflehog",
+			}
+			// Unverified results also come from detectors that cannot
+			// verify, so only a decided check or an error names the verifier
+			if result.Verified || result.VerificationError != "" {
+				sec.Verifier = "trufflehog"
+				sec.Verified = result.Verified
+				sec.VerificationError = result.VerificationError
+			}
+			secrets = append(secrets, sec)
+		}
+	}
+	return secrets, scanner.Err()
+}
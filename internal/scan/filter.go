@@ -0,0 +1,88 @@
+package scan
+
+import "strings"
+
+// PatternFilter selects a subset of patterns. Values within one field are
+// alternatives; every non-empty field must match. Comparisons ignore case.
+type PatternFilter struct {
+	Tags        []string // Keep patterns carrying any of these tags
+	ExcludeTags []string // Drop patterns carrying any of these tags
+	Severities  []string
+	Categories  []string // Category of the pattern file (cloud, payment, ...)
+	IDs         []string
+}
+
+// IsEmpty reports whether the filter keeps every pattern
+func (f PatternFilter) IsEmpty() bool {
+	return len(f.Tags) == 0 && len(f.ExcludeTags) == 0 && len(f.Severities) == 0 &&
+		len(f.Categories) == 0 && len(f.IDs) == 0
+}
+
+// Match reports whether the pattern passes the filter
+func (f PatternFilter) Match(p CompiledPattern) bool {
+	if len(f.IDs) > 0 && !containsFold(f.IDs, p.ID) {
+		return false
+	}
+	if len(f.Severities) > 0 && !containsFold(f.Severities, p.Severity) {
+		return false
+	}
+	if len(f.Categories) > 0 && !containsFold(f.Categories, p.Category) {
+		return false
+	}
+	if len(f.Tags) > 0 && !anyFold(f.Tags, p.Tags) {
+		return false
+	}
+	if len(f.ExcludeTags) > 0 && anyFold(f.ExcludeTags, p.Tags) {
+		return false
+	}
+	return true
+}
+
+// Apply returns the patterns that pass the filter
+func (f PatternFilter) Apply(patterns []CompiledPattern) []CompiledPattern {
+	if f.IsEmpty() {
+		return patterns
+	}
+
+	var result []CompiledPattern
+	for _, p := range patterns {
+		if f.Match(p) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// UnknownIDs returns the requested IDs that no pattern defines
+func (f PatternFilter) UnknownIDs(patterns []CompiledPattern) []string {
+	known := make(map[string]bool, len(patterns))
+	for _, p := range patterns {
+		known[strings.ToLower(p.ID)] = true
+	}
+
+	var unknown []string
+	for _, id := range f.IDs {
+		if !known[strings.ToLower(id)] {
+			unknown = append(unknown, id)
+		}
+	}
+	return unknown
+}
+
+func containsFold(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyFold(list, values []string) bool {
+	for _, v := range values {
+		if containsFold(list, v) {
+			return true
+		}
+	}
+	return false
+}
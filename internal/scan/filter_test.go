@@ -0,0 +1,64 @@
+package scan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPatternFilter(t *testing.T) {
+	patterns := []CompiledPattern{
+		{ID: "aws-access-key", Severity: "critical", Category: "cloud", Tags: []string{"aws", "keys"}},
+		{ID: "gcp-api-key", Severity: "high", Category: "cloud", Tags: []string{"gcp", "keys"}},
+		{ID: "stripe-secret-key", Severity: "critical", Category: "payment", Tags: []string{"stripe"}},
+		{ID: "slack-webhook", Severity: "medium", Category: "communication"},
+	}
+
+	tests := []struct {
+		name   string
+		filter PatternFilter
+		want   []string
+	}{
+		{"empty", PatternFilter{}, []string{"aws-access-key", "gcp-api-key", "stripe-secret-key", "slack-webhook"}},
+		{"tag", PatternFilter{Tags: []string{"keys"}}, []string{"aws-access-key", "gcp-api-key"}},
+		{"tag any of", PatternFilter{Tags: []string{"STRIPE", "gcp"}}, []string{"gcp-api-key", "stripe-secret-key"}},
+		{"exclude tag", PatternFilter{ExcludeTags: []string{"aws"}}, []string{"gcp-api-key", "stripe-secret-key", "slack-webhook"}},
+		{"severity", PatternFilter{Severities: []string{"Critical"}}, []string{"aws-access-key", "stripe-secret-key"}},
+		{"category", PatternFilter{Categories: []string{"cloud", "communication"}}, []string{"aws-access-key", "gcp-api-key", "slack-webhook"}},
+		{"id", PatternFilter{IDs: []string{"Slack-Webhook"}}, []string{"slack-webhook"}},
+		{"fields combine", PatternFilter{Severities: []string{"critical"}, Categories: []string{"cloud"}}, []string{"aws-access-key"}},
+		{"tag and exclude", PatternFilter{Tags: []string{"keys"}, ExcludeTags: []string{"gcp"}}, []string{"aws-access-key"}},
+		{"no match", PatternFilter{Severities: []string{"low"}}, nil},
+		{"untagged pattern", PatternFilter{Tags: []string{"webhook"}}, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, p := range tt.filter.Apply(patterns) {
+			got = append(got, p.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if !(PatternFilter{}).IsEmpty() {
+		t.Error("zero filter is not empty")
+	}
+	if (PatternFilter{ExcludeTags: []string{"aws"}}).IsEmpty() {
+		t.Error("filter with exclude tags is empty")
+	}
+	if got := (PatternFilter{}).Apply(nil); got != nil {
+		t.Errorf("empty filter over no patterns = %v", got)
+	}
+}
+
+func TestPatternFilterUnknownIDs(t *testing.T) {
+	patterns := []CompiledPattern{{ID: "aws-access-key"}, {ID: "gcp-api-key"}}
+
+	f := PatternFilter{IDs: []string{"AWS-Access-Key", "github-pat", "gcp-api-key"}}
+	if got, want := f.UnknownIDs(patterns), []string{"github-pat"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UnknownIDs = %v, want %v", got, want)
+	}
+	if got := (PatternFilter{}).UnknownIDs(patterns); got != nil {
+		t.Errorf("UnknownIDs without IDs = %v", got)
+	}
+}
@@ -0,0 +1,253 @@
+package scan
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/shaniidev/keyana/internal/core"
+)
+
+// IgnoreFileName is the allowlist file looked up in the output directory and
+// the user's home directory
+const IgnoreFileName = ".keyanaignore"
+
+// IgnoreList suppresses known findings. Each rule is one line of an ignore
+// file made of whitespace-separated terms, all of which must match:
+//
+//	value:<exact value>
+//	sha256:<hex SHA-256 of the value>
+//	regex:<regular expression matched against the value>
+//	file:<glob matched against the file path or URL>
+//	pattern:<pattern ID, globs allowed>
+//
+// so "pattern:gcp-api-key file:**/vendor/*.js" drops that pattern in vendor
+// files only. A value or regex term runs to the end of the line, spaces
+// included, so it comes last: "file:**/config.js value:hunter2 hunter2".
+// Blank lines and lines starting with # are skipped.
+type IgnoreList struct {
+	rules []ignoreRule
+}
+
+type ignoreRule struct {
+	source string // file:line, for error messages
+	terms  []ignoreTerm
+}
+
+type ignoreTerm struct {
+	kind  string
+	value string
+	re    *regexp.Regexp // Compiled regex or glob
+}
+
+// DefaultIgnoreFiles returns the per-user and per-output-dir ignore files
+func DefaultIgnoreFiles(outputDir string) []string {
+	var paths []string
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, IgnoreFileName))
+	}
+	return append(paths, filepath.Join(outputDir, IgnoreFileName))
+}
+
+// LoadIgnoreFiles reads and merges the given ignore files. Missing files are
+// skipped; invalid rules are returned as errors but do not stop loading.
+func LoadIgnoreFiles(paths ...string) (*IgnoreList, []error) {
+	list := &IgnoreList{}
+	var errs []error
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		errs = append(errs, list.Parse(f, path)...)
+		f.Close()
+	}
+	return list, errs
+}
+
+// Parse adds the rules read from r. source names the input in errors.
+func (l *IgnoreList) Parse(r io.Reader, source string) []error {
+	var errs []error
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{source: fmt.Sprintf("%s:%d", source, lineNo)}
+		var err error
+		for _, field := range ignoreFields(line) {
+			var term ignoreTerm
+			if term, err = parseIgnoreTerm(field); err != nil {
+				break
+			}
+			rule.terms = append(rule.terms, term)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", rule.source, err))
+			continue
+		}
+		l.rules = append(l.rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w", source, err))
+	}
+	return errs
+}
+
+// ignoreFields splits a rule into its terms. A value or regex term takes the
+// rest of the line.
+func ignoreFields(line string) []string {
+	var fields []string
+	for line != "" {
+		if strings.HasPrefix(line, "value:") || strings.HasPrefix(line, "regex:") {
+			return append(fields, line)
+		}
+		end := strings.IndexAny(line, " \t")
+		if end < 0 {
+			end = len(line)
+		}
+		fields = append(fields, line[:end])
+		line = strings.TrimLeft(line[end:], " \t")
+	}
+	return fields
+}
+
+func parseIgnoreTerm(field string) (ignoreTerm, error) {
+	kind, value, ok := strings.Cut(field, ":")
+	if !ok || value == "" {
+		return ignoreTerm{}, fmt.Errorf("%q: expected kind:value (value, sha256, regex, file or pattern)", field)
+	}
+
+	term := ignoreTerm{kind: kind, value: value}
+	var err error
+	switch kind {
+	case "value":
+	case "sha256":
+		term.value = strings.ToLower(value)
+		if _, err = hex.DecodeString(term.value); err != nil || len(term.value) != 64 {
+			return term, fmt.Errorf("%q: not a hex SHA-256", value)
+		}
+	case "regex":
+		term.re, err = regexp.Compile(value)
+	case "file", "pattern":
+		term.re, err = globToRegexp(value)
+	default:
+		return term, fmt.Errorf("unknown rule kind %q", kind)
+	}
+	if err != nil {
+		return term, fmt.Errorf("%s %q: %w", kind, value, err)
+	}
+	return term, nil
+}
+
+// globToRegexp converts a glob where * stays within one path segment, **
+// crosses segments and ? matches one character. A glob without a slash is
+// matched against the base name only.
+func globToRegexp(glob string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !strings.Contains(glob, "/") {
+		sb.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				sb.WriteString(".*")
+				i++
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+// Len returns the number of rules
+func (l *IgnoreList) Len() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.rules)
+}
+
+// Match reports whether any rule suppresses the finding
+func (l *IgnoreList) Match(sec core.Secret) bool {
+	if l == nil {
+		return false
+	}
+	for _, rule := range l.rules {
+		if rule.match(sec) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter splits findings into kept ones and the number suppressed
+func (l *IgnoreList) Filter(found []core.Secret) ([]core.Secret, int) {
+	if l.Len() == 0 {
+		return found, 0
+	}
+	var kept []core.Secret
+	for _, sec := range found {
+		if !l.Match(sec) {
+			kept = append(kept, sec)
+		}
+	}
+	return kept, len(found) - len(kept)
+}
+
+func (r ignoreRule) match(sec core.Secret) bool {
+	for _, t := range r.terms {
+		if !t.match(sec) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t ignoreTerm) match(sec core.Secret) bool {
+	switch t.kind {
+	case "value":
+		return sec.Value == t.value
+	case "sha256":
+		sum := sha256.Sum256([]byte(sec.Value))
+		return hex.EncodeToString(sum[:]) == t.value
+	case "regex":
+		return t.re.MatchString(sec.Value)
+	case "file":
+		if t.re.MatchString(filepath.ToSlash(sec.File)) {
+			return true
+		}
+		if sec.URL == "" {
+			return false
+		}
+		// Bundles are often served with a cache-busting query
+		u, _, _ := strings.Cut(sec.URL, "#")
+		path, _, _ := strings.Cut(u, "?")
+		return t.re.MatchString(u) || t.re.MatchString(path)
+	case "pattern":
+		return sec.PatternID != "" && t.re.MatchString(sec.PatternID)
+	}
+	return false
+}
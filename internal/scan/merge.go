@@ -0,0 +1,225 @@
+package scan
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/shaniidev/keyana/internal/core"
+)
+
+// lineSlack is how many lines apart two detectors may place the same
+// occurrence and still be merged
+const lineSlack = 1
+
+// severityRank orders severities; a finding without one ranks lowest
+var severityRank = map[string]int{"low": 1, "medium": 2, "high": 3, "critical": 4}
+
+// lineRange returns the first and last line a finding covers (0 = unknown)
+func lineRange(s core.Secret) (int, int) {
+	lo, hi := s.Line, s.Line
+	for _, l := range s.Locations {
+		if l.Line > 0 && (lo == 0 || l.Line < lo) {
+			lo = l.Line
+		}
+		if l.Line > hi {
+			hi = l.Line
+		}
+	}
+	return lo, hi
+}
+
+// better reports whether a is a better representative of a merged finding
+// than b: higher severity, then higher score, then higher base confidence
+func better(a, b core.Secret) bool {
+	if ra, rb := severityRank[a.Severity], severityRank[b.Severity]; ra != rb {
+		return ra > rb
+	}
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	return a.Confidence > b.Confidence
+}
+
+// MergeFindings folds the findings of all detectors that report the same
+// value in the same file at overlapping lines into one. Values are compared
+// after normalization. The merged finding takes its type, severity and
+// pattern from the best of the group, lists every detector in Detectors and
+// keeps every distinct location. Findings without a line join the nearest
+// group of their value and file. The result is sorted by file, line and value.
+func MergeFindings(found []core.Secret) []core.Secret {
+	type key struct{ value, file string }
+	groups := make(map[key][]core.Secret)
+	var order []key
+	for _, s := range found {
+		k := key{normalizeValue(s.Value), filepath.Clean(s.File)}
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], s)
+	}
+
+	var merged []core.Secret
+	for _, k := range order {
+		merged = append(merged, mergeGroup(groups[k])...)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		a, b := merged[i], merged[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Value < b.Value
+	})
+	return merged
+}
+
+// mergeGroup merges the findings of one value in one file whose line ranges
+// overlap. Findings without a line are placed afterwards, in the cluster
+// nearest to their offset or, without one, the first.
+func mergeGroup(group []core.Secret) []core.Secret {
+	var placed, unplaced []core.Secret
+	for _, s := range group {
+		if lo, _ := lineRange(s); lo > 0 {
+			placed = append(placed, s)
+		} else {
+			unplaced = append(unplaced, s)
+		}
+	}
+	sort.SliceStable(placed, func(i, j int) bool {
+		a, _ := lineRange(placed[i])
+		b, _ := lineRange(placed[j])
+		return a < b
+	})
+
+	var clusters [][]core.Secret
+	hi := 0
+	for _, s := range placed {
+		lo, end := lineRange(s)
+		n := len(clusters)
+		if n == 0 || lo > hi+lineSlack {
+			clusters = append(clusters, []core.Secret{s})
+			hi = end
+			continue
+		}
+		clusters[n-1] = append(clusters[n-1], s)
+		hi = max(hi, end)
+	}
+
+	for _, s := range unplaced {
+		if len(clusters) == 0 {
+			clusters = append(clusters, nil)
+		}
+		i := nearestCluster(clusters, s.Offset)
+		clusters[i] = append(clusters[i], s)
+	}
+
+	out := make([]core.Secret, 0, len(clusters))
+	for _, c := range clusters {
+		out = append(out, mergeCluster(c))
+	}
+	return out
+}
+
+// nearestCluster returns the index of the cluster with a known offset
+// closest to offset, or 0 when offset is unknown
+func nearestCluster(clusters [][]core.Secret, offset int) int {
+	if offset <= 0 {
+		return 0
+	}
+	best, bestDist := 0, -1
+	for i, c := range clusters {
+		for _, s := range c {
+			if s.Offset <= 0 {
+				continue
+			}
+			d := s.Offset - offset
+			if d < 0 {
+				d = -d
+			}
+			if bestDist < 0 || d < bestDist {
+				best, bestDist = i, d
+			}
+		}
+	}
+	return best
+}
+
+// mergeCluster folds findings of the same occurrence into the best of them
+func mergeCluster(c []core.Secret) core.Secret {
+	best := c[0]
+	for _, s := range c[1:] {
+		if better(s, best) {
+			best = s
+		}
+	}
+
+	best.Detectors = nil
+	seenDet := make(map[string]bool)
+	type pos struct{ line, col int }
+	seenLoc := make(map[pos]int) // Position -> index in locs
+	var locs []core.Location
+	for _, s := range c {
+		for _, d := range append([]string{s.Detector}, s.Detectors...) {
+			if d != "" && !seenDet[d] {
+				seenDet[d] = true
+				best.Detectors = append(best.Detectors, d)
+			}
+		}
+
+		sl := s.Locations
+		if len(sl) == 0 && s.Line > 0 {
+			sl = []core.Location{{Line: s.Line, Column: s.Column, Offset: s.Offset, EndOffset: s.EndOffset}}
+		}
+		for _, l := range sl {
+			p := pos{l.Line, l.Column}
+			i, seen := seenLoc[p]
+			switch {
+			case !seen:
+				seenLoc[p] = len(locs)
+				locs = append(locs, l)
+			case locs[i].EndOffset == 0 && l.EndOffset > 0:
+				// Keep the offsets of a detector that reports them
+				locs[i] = l
+			}
+		}
+
+		if s.Verified && !best.Verified {
+			best.Verifier, best.Verified, best.VerificationError = s.Verifier, true, ""
+		}
+		if best.Context == "" {
+			best.Context = s.Context
+		}
+		if best.PatternID == "" {
+			best.PatternID = s.PatternID
+		}
+	}
+	sort.Strings(best.Detectors)
+
+	// A detector that reports no column adds nothing to one that does
+	hasCol := make(map[int]bool)
+	for _, l := range locs {
+		if l.Column > 0 {
+			hasCol[l.Line] = true
+		}
+	}
+	kept := locs[:0]
+	for _, l := range locs {
+		if l.Column > 0 || !hasCol[l.Line] {
+			kept = append(kept, l)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		if kept[i].Line != kept[j].Line {
+			return kept[i].Line < kept[j].Line
+		}
+		return kept[i].Column < kept[j].Column
+	})
+
+	best.Locations = nil
+	for _, l := range kept {
+		best.AddLocation(l)
+	}
+	return best
+}
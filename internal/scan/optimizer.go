@@ -16,6 +16,28 @@ func ExtractKeyword(regexStr string) string {
 	return findBestLiteral(re)
 }
 
+// RegexLiterals returns every literal run of at least three characters in a
+// regex
+func RegexLiterals(regexStr string) []string {
+	re, err := syntax.Parse(regexStr, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	var literals []string
+	collectLiterals(re, &literals)
+	return literals
+}
+
+func collectLiterals(re *syntax.Regexp, literals *[]string) {
+	if re.Op == syntax.OpLiteral && len(re.Rune) >= 3 {
+		*literals = appendUnique(*literals, string(re.Rune))
+		return
+	}
+	for _, sub := range re.Sub {
+		collectLiterals(sub, literals)
+	}
+}
+
 func findBestLiteral(re *syntax.Regexp) string {
 	switch re.Op {
 	case syntax.OpLiteral:
@@ -49,6 +71,240 @@ func findBestLiteral(re *syntax.Regexp) string {
 	}
 }
 
+// Limits that keep literal-set expansion cheap
+const (
+	maxKeywordSet  = 16 // Max alternatives a pattern may be indexed under
+	maxClassExpand = 8  // Max characters a class may have to be expanded into literals
+)
+
+// ExtractKeywords analyzes a regex pattern and returns a set of literals at
+// least one of which MUST be present for the regex to match. Alternations
+// produce one literal per branch. When fold is true the pattern contains
+// case-insensitive parts; the returned literals are lowercased and must be
+// matched against a lowercased view of the content. Returns nil if no
+// usable set is found.
+func ExtractKeywords(regexStr string) (keywords []string, fold bool) {
+	re, err := syntax.Parse(regexStr, syntax.Perl)
+	if err != nil {
+		return nil, false
+	}
+	re = re.Simplify()
+	fold = hasFoldCase(re)
+	set := requiredSet(re, fold)
+	if !IsValidKeywordSet(set) {
+		return nil, fold
+	}
+	return set, fold
+}
+
+// IsValidKeywordSet checks that every alternative is a good index keyword
+func IsValidKeywordSet(set []string) bool {
+	if len(set) == 0 || len(set) > maxKeywordSet {
+		return false
+	}
+	for _, kw := range set {
+		if !IsValidKeyword(kw) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasFoldCase(re *syntax.Regexp) bool {
+	if re.Flags&syntax.FoldCase != 0 && (re.Op == syntax.OpLiteral || re.Op == syntax.OpCharClass) {
+		return true
+	}
+	for _, sub := range re.Sub {
+		if hasFoldCase(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredSet returns literals of which at least one must occur in any match
+func requiredSet(re *syntax.Regexp, fold bool) []string {
+	if exact := exactSet(re, fold); exact != nil {
+		return exact
+	}
+
+	switch re.Op {
+	case syntax.OpConcat:
+		// Expand runs of consecutive exact sub-expressions and keep the
+		// strongest usable candidate (longest shortest-alternative)
+		var best, run []string
+		consider := func(candidate []string) {
+			if IsValidKeywordSet(candidate) && betterSet(candidate, best) {
+				best = candidate
+			}
+		}
+		for _, sub := range re.Sub {
+			if exact := exactSet(sub, fold); exact != nil {
+				if next := crossSet(run, exact); next != nil {
+					run = next
+				} else {
+					consider(run)
+					run = exact
+				}
+				continue
+			}
+			consider(run)
+			run = nil
+			consider(requiredSet(sub, fold))
+		}
+		consider(run)
+		return best
+
+	case syntax.OpAlternate:
+		var union []string
+		for _, sub := range re.Sub {
+			set := requiredSet(sub, fold)
+			if set == nil {
+				return nil
+			}
+			union = appendUnique(union, set...)
+		}
+		if len(union) > maxKeywordSet {
+			return nil
+		}
+		return union
+
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredSet(re.Sub[0], fold)
+
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			return requiredSet(re.Sub[0], fold)
+		}
+		return nil
+
+	default:
+		return nil
+	}
+}
+
+// exactSet returns every string re can match if that set is small and
+// finite, otherwise nil
+func exactSet(re *syntax.Regexp, fold bool) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		lit := string(re.Rune)
+		if fold {
+			lit = strings.ToLower(lit)
+		}
+		return []string{lit}
+
+	case syntax.OpCharClass:
+		var set []string
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			for r := re.Rune[i]; r <= re.Rune[i+1]; r++ {
+				c := string(r)
+				if fold {
+					c = strings.ToLower(c)
+				}
+				set = appendUnique(set, c)
+				if len(set) > maxClassExpand {
+					return nil
+				}
+			}
+		}
+		return set
+
+	case syntax.OpCapture:
+		return exactSet(re.Sub[0], fold)
+
+	case syntax.OpConcat:
+		var set []string
+		for _, sub := range re.Sub {
+			exact := exactSet(sub, fold)
+			if exact == nil {
+				return nil
+			}
+			if set = crossSet(set, exact); set == nil {
+				return nil
+			}
+		}
+		return set
+
+	case syntax.OpAlternate:
+		var union []string
+		for _, sub := range re.Sub {
+			exact := exactSet(sub, fold)
+			if exact == nil {
+				return nil
+			}
+			union = appendUnique(union, exact...)
+		}
+		if len(union) > maxKeywordSet {
+			return nil
+		}
+		return union
+
+	default:
+		return nil
+	}
+}
+
+// crossSet concatenates every element of a with every element of b.
+// A nil a acts as the identity. Returns nil if the result is too large.
+func crossSet(a, b []string) []string {
+	if a == nil {
+		return b
+	}
+	if len(a)*len(b) > maxKeywordSet {
+		return nil
+	}
+	var out []string
+	for _, x := range a {
+		for _, y := range b {
+			out = appendUnique(out, x+y)
+		}
+	}
+	return out
+}
+
+// betterSet prefers the set whose shortest alternative is longest, then the
+// smaller set
+func betterSet(candidate, best []string) bool {
+	if len(candidate) == 0 {
+		return false
+	}
+	if len(best) == 0 {
+		return true
+	}
+	cMin, bMin := minLen(candidate), minLen(best)
+	if cMin != bMin {
+		return cMin > bMin
+	}
+	return len(candidate) < len(best)
+}
+
+func minLen(set []string) int {
+	m := -1
+	for _, s := range set {
+		if m < 0 || len(s) < m {
+			m = len(s)
+		}
+	}
+	return m
+}
+
+func appendUnique(set []string, items ...string) []string {
+	for _, item := range items {
+		dup := false
+		for _, s := range set {
+			if s == item {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			set = append(set, item)
+		}
+	}
+	return set
+}
+
 // IsValidKeyword checks if the keyword is good enough to be an index
 func IsValidKeyword(kw string) bool {
 	// Must be at least 4 chars to be worth indexing
@@ -0,0 +1,68 @@
+package scan
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtractKeywords(t *testing.T) {
+	tests := []struct {
+		name  string
+		regex string
+		want  []string // nil = the pattern falls back to running on every file
+		fold  bool
+	}{
+		{"literal prefix", `ghp_[A-Za-z0-9]{36}`, []string{"ghp_"}, false},
+		{"alternation", `(ghp|gho|ghu)_[A-Za-z0-9]{36}`, []string{"gho_", "ghp_", "ghu_"}, false},
+		{"alternation of unrelated branches", `(stripe|square)_key_[a-z0-9]{24}`, []string{"square_key_", "stripe_key_"}, false},
+		{"fold flag", `(?i)stripe_[a-z]+`, []string{"stripe_"}, true},
+		{"fold group", `(?i:slack)_TOKEN=[a-z0-9]{8}`, []string{"slack_token="}, true},
+		{"small class expands", `xox[baprs]-[0-9]{10}`, []string{"xoxa-", "xoxb-", "xoxp-", "xoxr-", "xoxs-"}, false},
+		{"class at the expansion limit", `xox[a-h]-[0-9]{10}`, []string{"xoxa-", "xoxb-", "xoxc-", "xoxd-", "xoxe-", "xoxf-", "xoxg-", "xoxh-"}, false},
+		{"class over the expansion limit", `xox[a-i]-token[0-9]{10}`, []string{"-token"}, false},
+		{"required repeated group", `(?:sk_live_)+[a-z]{8}`, []string{"sk_live_"}, false},
+		{"bounded repeat", `(AKIA){1,2}[A-Z0-9]{16}`, []string{"AKIA"}, false},
+		{"optional prefix skipped", `[a-z]{0,3}(AKIA)[A-Z0-9]{16}`, []string{"AKIA"}, false},
+
+		{"optional group only", `(sk_live_)?[a-z0-9]{24}`, nil, false},
+		{"short literals", `(?i)api[_-]?key\s*[:=]\s*[a-z0-9]{32}`, nil, true},
+		{"common word", `(?i)token\s*[:=]\s*\S+`, nil, true},
+		{"too many alternatives", `(alpha|bravo|charlie|delta|echo|foxtrot|golf|hotel|india|juliet|kilo|lima|mike|november|oscar|papa|quebec)_[0-9]{8}`, nil, false},
+		{"no literal", `[A-Za-z0-9]{40}`, nil, false},
+		{"invalid regex", `(unclosed`, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, fold := ExtractKeywords(tt.regex)
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) || (got == nil) != (tt.want == nil) {
+				t.Errorf("keywords %q, want %q", got, tt.want)
+			}
+			if fold != tt.fold {
+				t.Errorf("fold %v, want %v", fold, tt.fold)
+			}
+		})
+	}
+}
+
+func TestEngineStats(t *testing.T) {
+	e := NewEngine([]CompiledPattern{
+		mustCompile(t, PatternTemplate{ID: "single", Regex: `ghp_[A-Za-z0-9]{36}`}),
+		mustCompile(t, PatternTemplate{ID: "set", Regex: `(ghp|gho)_[A-Za-z0-9]{36}`}), // Best single literal "gh" is too short
+		mustCompile(t, PatternTemplate{ID: "folded", Regex: `(?i)stripe_[a-z]{24}`}),
+		mustCompile(t, PatternTemplate{ID: "fallback", Regex: `(?i)api[_-]?key\s*[:=]\s*[a-z0-9]{32}`}),
+	})
+
+	want := EngineStats{Indexed: 3, FoldIndexed: 1, Promoted: 1, Fallbacks: 1, Keywords: 2, FoldKeywords: 1}
+	if e.Stats != want {
+		t.Errorf("stats %+v, want %+v", e.Stats, want)
+	}
+
+	// Every indexed pattern runs only when one of its keywords occurs
+	content := []byte(`a = "GHO_x"; b = "STRIPE_abc"`)
+	for i, want := range []bool{false, false, true, true} {
+		if got := e.Triggers(i, content); got != want {
+			t.Errorf("%s: triggered %v, want %v", e.AllPatterns[i].ID, got, want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package scan
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Names under which the generic heuristics are profiled alongside patterns
+const (
+	ProfileGenericAssignment = "heuristic:generic-assignment"
+	ProfileEntropyLiteral    = "heuristic:entropy-literal"
+)
+
+// maxSlowFiles caps how many over-budget files are kept per pattern
+const maxSlowFiles = 10
+
+// PatternStats accumulates the cost of one pattern across a scan
+type PatternStats struct {
+	ID          string
+	Invocations int
+	TotalTime   time.Duration
+	MaxTime     time.Duration
+	MaxFile     string // File that produced MaxTime
+	Bytes       int64  // Content bytes the pattern ran over
+	Matches     int    // Raw regex matches, before filters
+	OverBudget  int    // Files on which a single run exceeded the budget
+	SlowFiles   []SlowRun
+}
+
+// SlowRun is a single pattern run that exceeded the time budget
+type SlowRun struct {
+	File     string
+	Duration time.Duration
+	Bytes    int
+}
+
+// PatternProfiler records per-pattern timing. Pass it in ScanOptions to
+// enable profiling; it is safe for concurrent use by scan workers.
+type PatternProfiler struct {
+	Budget  time.Duration // A single run slower than this is flagged (0 disables)
+	entries map[string]*profileEntry
+}
+
+type profileEntry struct {
+	mu    sync.Mutex
+	stats PatternStats
+}
+
+// NewPatternProfiler prepares a profiler for the given patterns and the
+// generic heuristics
+func NewPatternProfiler(patterns []CompiledPattern, budget time.Duration) *PatternProfiler {
+	p := &PatternProfiler{
+		Budget:  budget,
+		entries: make(map[string]*profileEntry, len(patterns)+2),
+	}
+	for _, pat := range patterns {
+		p.entries[pat.ID] = &profileEntry{stats: PatternStats{ID: pat.ID}}
+	}
+	for _, id := range []string{ProfileGenericAssignment, ProfileEntropyLiteral} {
+		p.entries[id] = &profileEntry{stats: PatternStats{ID: id}}
+	}
+	return p
+}
+
+// Record adds one run of the pattern with the given ID. Unknown IDs are
+// ignored, so a profiler built for a different pattern set is harmless.
+func (p *PatternProfiler) Record(id, file string, elapsed time.Duration, size, matches int) {
+	if p == nil {
+		return
+	}
+	e, ok := p.entries[id]
+	if !ok {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s := &e.stats
+	s.Invocations++
+	s.TotalTime += elapsed
+	s.Bytes += int64(size)
+	s.Matches += matches
+	if elapsed > s.MaxTime {
+		s.MaxTime = elapsed
+		s.MaxFile = file
+	}
+	if p.Budget > 0 && elapsed > p.Budget {
+		s.OverBudget++
+		if len(s.SlowFiles) < maxSlowFiles {
+			s.SlowFiles = append(s.SlowFiles, SlowRun{File: file, Duration: elapsed, Bytes: size})
+		}
+	}
+}
+
+// Results returns a snapshot of every pattern that ran at least once,
+// slowest total time first
+func (p *PatternProfiler) Results() []PatternStats {
+	var results []PatternStats
+	for _, e := range p.entries {
+		e.mu.Lock()
+		s := e.stats
+		s.SlowFiles = append([]SlowRun(nil), s.SlowFiles...)
+		e.mu.Unlock()
+
+		if s.Invocations > 0 {
+			results = append(results, s)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].TotalTime != results[j].TotalTime {
+			return results[i].TotalTime > results[j].TotalTime
+		}
+		return results[i].ID < results[j].ID
+	})
+	return results
+}
@@ -0,0 +1,97 @@
+package scan
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPatternProfiler(t *testing.T) {
+	const ms = time.Millisecond
+	p := NewPatternProfiler([]CompiledPattern{{ID: "fast"}, {ID: "slow"}, {ID: "tie-b"}, {ID: "tie-a"}, {ID: "idle"}}, 50*ms)
+
+	p.Record("fast", "a.js", 2*ms, 100, 1)
+	p.Record("fast", "b.js", 3*ms, 200, 0)
+	p.Record("slow", "a.js", 40*ms, 100, 0)
+	p.Record("slow", "big.js", 80*ms, 9000, 3)
+	p.Record("slow", "b.js", 50*ms, 200, 0) // At the budget is not over it
+	p.Record("tie-b", "a.js", 10*ms, 100, 0)
+	p.Record("tie-a", "a.js", 10*ms, 100, 0)
+	p.Record(ProfileEntropyLiteral, "big.js", 60*ms, 9000, 12)
+	p.Record("unknown", "a.js", time.Second, 100, 0)
+
+	results := p.Results()
+	var order []string
+	for _, s := range results {
+		order = append(order, s.ID)
+	}
+	// Slowest total first, ties by ID; patterns that never ran are left out
+	if want := []string{"slow", ProfileEntropyLiteral, "tie-a", "tie-b", "fast"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("order %v, want %v", order, want)
+	}
+
+	slow := results[0]
+	if slow.Invocations != 3 || slow.TotalTime != 170*ms || slow.MaxTime != 80*ms || slow.MaxFile != "big.js" ||
+		slow.Bytes != 9300 || slow.Matches != 3 {
+		t.Errorf("slow stats %+v", slow)
+	}
+	if want := []SlowRun{{File: "big.js", Duration: 80 * ms, Bytes: 9000}}; slow.OverBudget != 1 || !reflect.DeepEqual(slow.SlowFiles, want) {
+		t.Errorf("slow over budget %d on %+v", slow.OverBudget, slow.SlowFiles)
+	}
+
+	var flagged []string
+	for _, s := range results {
+		if s.OverBudget > 0 {
+			flagged = append(flagged, s.ID)
+		}
+	}
+	if want := []string{"slow", ProfileEntropyLiteral}; !reflect.DeepEqual(flagged, want) {
+		t.Errorf("over budget %v, want %v", flagged, want)
+	}
+
+	// Results is a snapshot
+	results[0].SlowFiles[0].File = "changed"
+	if p.Results()[0].SlowFiles[0].File != "big.js" {
+		t.Error("Results shares SlowFiles with the profiler")
+	}
+}
+
+func TestPatternProfilerLimits(t *testing.T) {
+	p := NewPatternProfiler([]CompiledPattern{{ID: "p"}}, time.Millisecond)
+	for i := 0; i < maxSlowFiles+5; i++ {
+		p.Record("p", fmt.Sprintf("%d.js", i), 2*time.Millisecond, 10, 0)
+	}
+	if s := p.Results()[0]; s.OverBudget != maxSlowFiles+5 || len(s.SlowFiles) != maxSlowFiles {
+		t.Errorf("over budget %d with %d slow files kept", s.OverBudget, len(s.SlowFiles))
+	}
+
+	// A zero budget flags nothing
+	p = NewPatternProfiler([]CompiledPattern{{ID: "p"}}, 0)
+	p.Record("p", "a.js", time.Hour, 10, 0)
+	if s := p.Results()[0]; s.OverBudget != 0 || s.SlowFiles != nil {
+		t.Errorf("zero budget flagged %+v", s)
+	}
+
+	// Scan workers record concurrently
+	p = NewPatternProfiler([]CompiledPattern{{ID: "p"}}, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				p.Record("p", "a.js", time.Microsecond, 1, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if s := p.Results()[0]; s.Invocations != 800 || s.Matches != 800 || s.TotalTime != 800*time.Microsecond {
+		t.Errorf("concurrent stats %+v", s)
+	}
+
+	// A nil profiler ignores records
+	var nilProfiler *PatternProfiler
+	nilProfiler.Record("p", "a.js", time.Second, 1, 0)
+}
@@ -0,0 +1,58 @@
+package scan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// RedactMode controls how secret values appear in reports
+type RedactMode string
+
+const (
+	RedactNone    RedactMode = "none"    // Plaintext
+	RedactPartial RedactMode = "partial" // First and last 4 characters
+	RedactHash    RedactMode = "hash"    // Truncated SHA-256 of the value
+)
+
+// ParseRedactMode validates a -redact value. An empty value is the default,
+// partial redaction.
+func ParseRedactMode(s string) (RedactMode, error) {
+	switch m := RedactMode(strings.ToLower(s)); m {
+	case RedactNone, RedactPartial, RedactHash:
+		return m, nil
+	case "":
+		return RedactPartial, nil
+	case "off":
+		return RedactNone, nil
+	}
+	return RedactPartial, fmt.Errorf("unknown redaction mode %q (use partial, hash or none)", s)
+}
+
+// Value returns the redacted form of a secret value
+func (m RedactMode) Value(v string) string {
+	switch m {
+	case RedactPartial:
+		// Short values would be mostly revealed by 4+4 characters
+		if len(v) <= 12 {
+			if len(v) <= 2 {
+				return strings.Repeat("*", len(v))
+			}
+			return v[:2] + strings.Repeat("*", len(v)-2)
+		}
+		return fmt.Sprintf("%s...%s (%d chars)", v[:4], v[len(v)-4:], len(v))
+	case RedactHash:
+		sum := sha256.Sum256([]byte(v))
+		return "sha256:" + hex.EncodeToString(sum[:])[:16]
+	}
+	return v
+}
+
+// Text redacts every occurrence of value inside text, e.g. a match context
+func (m RedactMode) Text(text, value string) string {
+	if m == RedactNone || value == "" {
+		return text
+	}
+	return strings.ReplaceAll(text, value, m.Value(value))
+}
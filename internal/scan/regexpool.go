@@ -0,0 +1,68 @@
+package scan
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/coregx/coregex"
+)
+
+// regexSource lends one pattern's regex to a single match at a time
+type regexSource interface {
+	get() *coregex.Regexp
+	put(re *coregex.Regexp)
+}
+
+// regexPool hands out private clones of one pattern's regex. The coregex lazy
+// DFA caches state on the Regexp and is not safe for concurrent use, so every
+// worker matching the pattern borrows its own clone instead of locking a
+// shared instance. The regex compiled when the pattern was loaded is lent
+// first; further clones are compiled from it on demand. At most one idle
+// clone per CPU is kept for reuse and the rest are left to the GC, so a burst
+// of workers does not pin patterns x workers DFA caches.
+type regexPool struct {
+	seed   *coregex.Regexp // The loaded regex, lent under seedMu
+	seedMu sync.Mutex
+	max    int        // Idle clones kept for reuse
+	mu     sync.Mutex // Guards free only; never held while matching
+	free   []*coregex.Regexp
+}
+
+func newRegexPool(seed *coregex.Regexp) *regexPool {
+	return &regexPool{seed: seed, max: runtime.GOMAXPROCS(0)}
+}
+
+// get returns an idle clone, the seed if it is free, or a new clone. Should a
+// clone fail to compile, callers wait for the seed instead.
+func (p *regexPool) get() *coregex.Regexp {
+	p.mu.Lock()
+	if n := len(p.free); n > 0 {
+		re := p.free[n-1]
+		p.free = p.free[:n-1]
+		p.mu.Unlock()
+		return re
+	}
+	p.mu.Unlock()
+
+	if p.seedMu.TryLock() {
+		return p.seed
+	}
+	if re, err := coregex.Compile(p.seed.String()); err == nil {
+		return re
+	}
+	p.seedMu.Lock()
+	return p.seed
+}
+
+// put returns a clone to the pool, dropping it if the pool is full
+func (p *regexPool) put(re *coregex.Regexp) {
+	if re == p.seed {
+		p.seedMu.Unlock()
+		return
+	}
+	p.mu.Lock()
+	if len(p.free) < p.max {
+		p.free = append(p.free, re)
+	}
+	p.mu.Unlock()
+}
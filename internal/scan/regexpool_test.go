@@ -0,0 +1,44 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/coregx/coregex"
+)
+
+func TestRegexPoolKeepsAtMostMaxIdle(t *testing.T) {
+	seed := coregex.MustCompile(`key_[a-z]{4}`)
+	p := newRegexPool(seed)
+	p.max = 2
+
+	var lent []*coregex.Regexp
+	for i := 0; i < 5; i++ {
+		lent = append(lent, p.get())
+	}
+	if lent[0] != seed {
+		t.Error("the loaded regex was not lent first")
+	}
+	for i := 1; i < len(lent); i++ {
+		if lent[i] == lent[i-1] || lent[i] == seed {
+			t.Fatal("concurrent borrowers share a clone")
+		}
+	}
+	for _, re := range lent {
+		p.put(re)
+	}
+	if len(p.free) != 2 {
+		t.Errorf("%d idle clones kept, want 2", len(p.free))
+	}
+	if re := p.get(); re != lent[2] {
+		t.Error("get did not reuse an idle clone")
+	}
+
+	// The seed went back to the pool and can be lent again
+	p.free = nil
+	if re := p.get(); re != seed {
+		t.Error("returned seed was not lent again")
+	}
+	if re := p.get(); re == seed {
+		t.Error("seed lent twice at once")
+	}
+}
@@ -4,30 +4,79 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/shaniidev/keyana/internal/config"
 	"github.com/shaniidev/keyana/internal/core"
 )
 
-func SaveSecrets(secrets []core.Secret, cfg *config.Config) {
+// SecretReport is the content of one secrets report
+type SecretReport struct {
+	Secrets    []core.Secret // Findings to list (only new ones in baseline mode)
+	Suppressed int           // Findings dropped by ignore rules
+
+	// Baseline mode
+	Baseline     string          // Baseline file compared against ("" = off)
+	Unchanged    int             // Findings already in the baseline
+	Resolved     []BaselineEntry // Baseline findings no longer present
+	ShowResolved bool
+
+	Redact RedactMode // How secret values are shown
+}
+
+// SaveSecrets appends the findings to the secrets report
+func SaveSecrets(report SecretReport, cfg *config.Config) {
+	outputPath := filepath.Join(cfg.OutputDir, "reports", "secrets.txt")
+	f, err := os.OpenFile(outputPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		fmt.Printf("[-] Error opening secrets report: %v\n", err)
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(FormatSecretReport(report)); err != nil {
+		fmt.Printf("[-] Error saving secrets: %v\n", err)
+	} else {
+		fmt.Printf("[+] Secrets Report Appended: %s\n", outputPath)
+	}
+}
+
+// FormatSecretReport renders a secrets report as text
+func FormatSecretReport(report SecretReport) string {
+	secrets := report.Secrets
+
 	// Group secrets by detector
 	detectorGroups := make(map[string][]core.Secret)
 	for _, s := range secrets {
-		detectorGroups[s.Detector] = append(detectorGroups[s.Detector], s)
+		section := reportSection(s)
+		detectorGroups[section] = append(detectorGroups[section], s)
 	}
 
 	var sb strings.Builder
 	sb.WriteString(strings.Repeat("=", 80) + "\n")
 	sb.WriteString("KEYANA - SECRET DETECTION REPORT\n")
 	sb.WriteString(strings.Repeat("=", 80) + "\n\n")
-	sb.WriteString(fmt.Sprintf("Total Secrets Found: %d\n\n", len(secrets)))
+	if report.Baseline != "" {
+		sb.WriteString(fmt.Sprintf("Baseline: %s\n", report.Baseline))
+		sb.WriteString(fmt.Sprintf("New Secrets: %d (unchanged: %d, resolved: %d)\n", len(secrets), report.Unchanged, len(report.Resolved)))
+	} else {
+		sb.WriteString(fmt.Sprintf("Total Secrets Found: %d\n", len(secrets)))
+	}
+	sb.WriteString(fmt.Sprintf("Suppressed by %s: %d\n", IgnoreFileName, report.Suppressed))
+	if report.Redact != RedactNone {
+		sb.WriteString(fmt.Sprintf("Redaction: %s\n", report.Redact))
+	}
+	sb.WriteString("\n")
 
 	// Define detector order for consistent output (all sections shown)
 	detectorOrder := []string{
 		"Template (critical)",
 		"Template (high)",
 		"Template (medium)",
+		"Template (low)",
 		"Regex (Vendor)",
 		"Regex (Entropy)",
 		"Entropy (Pure)",
@@ -36,7 +85,16 @@ func SaveSecrets(secrets []core.Secret, cfg *config.Config) {
 		"trufflehog",
 	}
 
-	for _, detector := range detectorOrder {
+	// Detectors outside the fixed order (a new external scanner) follow it
+	var extra []string
+	for section := range detectorGroups {
+		if !slices.Contains(detectorOrder, section) {
+			extra = append(extra, section)
+		}
+	}
+	sort.Strings(extra)
+
+	for _, detector := range append(detectorOrder, extra...) {
 		findings, exists := detectorGroups[detector]
 		count := 0
 		if exists {
@@ -53,31 +111,98 @@ func SaveSecrets(secrets []core.Secret, cfg *config.Config) {
 			continue
 		}
 
-		// List findings
+		// List findings, most credible first
+		sort.SliceStable(findings, func(i, j int) bool { return findings[i].Score > findings[j].Score })
 		for i, s := range findings {
 			fmt.Fprintf(&sb, "[Finding #%d]\n", i+1)
 			fmt.Fprintf(&sb, "  File: %s\n", s.File)
+			if s.URL != "" {
+				fmt.Fprintf(&sb, "  URL: %s\n", s.URL)
+			}
+			if s.SHA256 != "" {
+				fmt.Fprintf(&sb, "  SHA256: %s\n", s.SHA256)
+			}
+			if s.Origin != "" {
+				fmt.Fprintf(&sb, "  Original Source: %s\n", s.Origin)
+			}
 			fmt.Fprintf(&sb, "  Type: %s\n", s.Type)
+			if len(s.Detectors) > 1 {
+				fmt.Fprintf(&sb, "  Detectors: %s\n", strings.Join(s.Detectors, ", "))
+			}
+			if s.PatternID != "" {
+				fmt.Fprintf(&sb, "  Pattern: %s\n", s.PatternID)
+			}
+			if s.Severity != "" {
+				fmt.Fprintf(&sb, "  Severity: %s\n", s.Severity)
+			}
+			fmt.Fprintf(&sb, "  Confidence: %d (base %d)\n", s.Score, s.Confidence)
+			switch {
+			case s.Verifier == "":
+			case s.VerificationError != "":
+				fmt.Fprintf(&sb, "  Verified: unknown (%s)\n", s.VerificationError)
+			case s.Verified:
+				fmt.Fprintf(&sb, "  Verified: LIVE (%s)\n", s.Verifier)
+			default:
+				fmt.Fprintf(&sb, "  Verified: invalid (%s)\n", s.Verifier)
+			}
 			fmt.Fprintf(&sb, "  Line: %d\n", s.Line)
-			fmt.Fprintf(&sb, "  Secret: %s\n", s.Value)
+			if s.Column > 0 {
+				fmt.Fprintf(&sb, "  Column: %d (offset %d)\n", s.Column, s.Offset)
+			}
+			if s.RawLine > 0 {
+				fmt.Fprintf(&sb, "  Raw Position: line %d, column %d", s.RawLine, s.RawColumn)
+				if s.RawFile != "" {
+					fmt.Fprintf(&sb, " in %s", s.RawFile)
+				}
+				sb.WriteString("\n")
+			}
+			if len(s.Locations) > 1 {
+				var locs []string
+				for _, l := range s.Locations {
+					loc := fmt.Sprintf("%d:%d", l.Line, l.Column)
+					if l.RawLine > 0 {
+						loc += fmt.Sprintf(" (raw %d:%d)", l.RawLine, l.RawColumn)
+					}
+					locs = append(locs, loc)
+				}
+				fmt.Fprintf(&sb, "  Occurrences: %d (%s)\n", len(s.Locations), strings.Join(locs, ", "))
+			}
+			if s.Decoded != "" {
+				fmt.Fprintf(&sb, "  Decoded: %s\n", s.Decoded)
+			}
+			fmt.Fprintf(&sb, "  Secret: %s\n", report.Redact.Value(s.Value))
+			if s.Context != "" && s.Context != s.Value {
+				fmt.Fprintf(&sb, "  Match: %s\n", report.Redact.Text(s.Context, s.Value))
+			}
 			sb.WriteString("\n")
 		}
 		sb.WriteString("\n")
 	}
 
-	outputPath := filepath.Join(cfg.OutputDir, "reports", "secrets.txt")
-	f, err := os.OpenFile(outputPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		fmt.Printf("[-] Error opening secrets report: %v\n", err)
-		return
+	if report.Baseline != "" && report.ShowResolved {
+		sb.WriteString(strings.Repeat("-", 80) + "\n")
+		sb.WriteString(fmt.Sprintf("RESOLVED SINCE BASELINE (%d findings)\n", len(report.Resolved)))
+		sb.WriteString(strings.Repeat("-", 80) + "\n\n")
+		if len(report.Resolved) == 0 {
+			sb.WriteString("  No baseline finding disappeared.\n\n")
+		}
+		for i, e := range report.Resolved {
+			fmt.Fprintf(&sb, "[Resolved #%d]\n", i+1)
+			fmt.Fprintf(&sb, "  File: %s\n", e.File)
+			if e.URL != "" {
+				fmt.Fprintf(&sb, "  URL: %s\n", e.URL)
+			}
+			fmt.Fprintf(&sb, "  Type: %s\n", e.Type)
+			fmt.Fprintf(&sb, "  Detector: %s\n", e.Detector)
+			value := e.Value
+			if !e.Redacted {
+				value = report.Redact.Value(value)
+			}
+			fmt.Fprintf(&sb, "  Secret: %s\n\n", value)
+		}
 	}
-	defer f.Close()
 
-	if _, err := f.WriteString(sb.String()); err != nil {
-		fmt.Printf("[-] Error saving secrets: %v\n", err)
-	} else {
-		fmt.Printf("[+] Secrets Report Appended: %s\n", outputPath)
-	}
+	return sb.String()
 }
 
 func SaveEndpoints(endpoints []core.Endpoint, cfg *config.Config) {
@@ -108,12 +233,27 @@ func SaveEndpoints(endpoints []core.Endpoint, cfg *config.Config) {
 		sb.WriteString(fmt.Sprintf("SOURCE: %s (%d endpoints)\n", strings.ToUpper(source), len(findings)))
 		sb.WriteString(strings.Repeat("-", 80) + "\n\n")
 
-		// Deduplicate paths within this source
-		uniquePaths := make(map[string]bool)
+		// Deduplicate paths within this source, listing every file they
+		// were served in
+		var paths []string
+		served := make(map[string][]string)
 		for _, e := range findings {
-			if !uniquePaths[e.Path] {
-				uniquePaths[e.Path] = true
-				fmt.Fprintf(&sb, "%s\n", e.Path)
+			if _, ok := served[e.Path]; !ok {
+				paths = append(paths, e.Path)
+				served[e.Path] = nil
+			}
+			if e.URL == "" {
+				continue
+			}
+			from := fmt.Sprintf("%s (sha256:%s)", e.URL, e.SHA256)
+			if !slices.Contains(served[e.Path], from) {
+				served[e.Path] = append(served[e.Path], from)
+			}
+		}
+		for _, p := range paths {
+			fmt.Fprintf(&sb, "%s\n", p)
+			for _, from := range served[p] {
+				fmt.Fprintf(&sb, "    from %s\n", from)
 			}
 		}
 		sb.WriteString("\n")
@@ -134,6 +274,77 @@ func SaveEndpoints(endpoints []core.Endpoint, cfg *config.Config) {
 	}
 }
 
+// reportSection names the report section a finding is listed under.
+// Template findings are split by severity.
+func reportSection(s core.Secret) string {
+	if s.Detector == "Template" {
+		return fmt.Sprintf("Template (%s)", s.Severity)
+	}
+	return s.Detector
+}
+
+// SaveProfile writes the per-pattern timing report, slowest patterns first
+func SaveProfile(profiler *PatternProfiler, cfg *config.Config) {
+	results := profiler.Results()
+
+	var sb strings.Builder
+	sb.WriteString(strings.Repeat("=", 110) + "\n")
+	sb.WriteString("KEYANA - PATTERN PROFILE\n")
+	sb.WriteString(strings.Repeat("=", 110) + "\n\n")
+	fmt.Fprintf(&sb, "Generated: %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&sb, "Patterns run: %d\n", len(results))
+	if profiler.Budget > 0 {
+		fmt.Fprintf(&sb, "Time budget per file: %v\n", profiler.Budget)
+	}
+	sb.WriteString("\n")
+
+	fmt.Fprintf(&sb, "%-45s %8s %12s %12s %12s %10s %8s\n", "PATTERN", "CALLS", "TOTAL", "MAX", "BYTES", "MATCHES", "SLOW")
+	sb.WriteString(strings.Repeat("-", 110) + "\n")
+	for _, r := range results {
+		fmt.Fprintf(&sb, "%-45s %8d %12v %12v %12d %10d %8d\n",
+			r.ID, r.Invocations, r.TotalTime.Round(time.Microsecond), r.MaxTime.Round(time.Microsecond),
+			r.Bytes, r.Matches, r.OverBudget)
+	}
+	sb.WriteString("\n")
+
+	// Over-budget runs, i.e. candidates for a ReDoS fix or demotion
+	sb.WriteString(strings.Repeat("-", 110) + "\n")
+	sb.WriteString("PATTERNS OVER BUDGET\n")
+	sb.WriteString(strings.Repeat("-", 110) + "\n\n")
+	flagged := 0
+	for _, r := range results {
+		if r.OverBudget == 0 {
+			continue
+		}
+		flagged++
+		fmt.Fprintf(&sb, "[%s] %d slow runs, max %v\n", r.ID, r.OverBudget, r.MaxTime.Round(time.Microsecond))
+		for _, run := range r.SlowFiles {
+			fmt.Fprintf(&sb, "  %v  %d bytes  %s\n", run.Duration.Round(time.Microsecond), run.Bytes, run.File)
+		}
+		if r.OverBudget > len(r.SlowFiles) {
+			fmt.Fprintf(&sb, "  ... %d more\n", r.OverBudget-len(r.SlowFiles))
+		}
+		sb.WriteString("\n")
+	}
+	if flagged == 0 {
+		sb.WriteString("  No pattern exceeded the budget.\n\n")
+	}
+
+	outputPath := filepath.Join(cfg.OutputDir, "reports", "pattern_profile.txt")
+	f, err := os.OpenFile(outputPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		fmt.Printf("[-] Error opening pattern profile: %v\n", err)
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(sb.String()); err != nil {
+		fmt.Printf("[-] Error saving pattern profile: %v\n", err)
+	} else {
+		fmt.Printf("[+] Pattern Profile Appended: %s\n", outputPath)
+	}
+}
+
 // SaveScanLog saves detailed scan logs to logs folder
 func SaveScanLog(logName string, content string, cfg *config.Config) {
 	logPath := filepath.Join(cfg.OutputDir, "logs", logName)
@@ -0,0 +1,39 @@
+package scan
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shaniidev/keyana/internal/core"
+)
+
+// Every finding lands in a section of the report, including detectors the
+// fixed section order does not name
+func TestFormatSecretReportSections(t *testing.T) {
+	secrets := []core.Secret{
+		{Detector: "Template", Severity: "critical", Type: "AWS", Value: "AKIAQZ0KX9M2WL4PQRST", File: "app.js", Line: 3},
+		{Detector: "semgrep", Type: "Hardcoded Secret", Value: "Qz0Kx9M2wl4pRt8V", File: "app.js", Line: 9},
+		{Detector: "Template", Type: "Custom", Value: "custom_abcdefgh", File: "app.js", Line: 12},
+	}
+	report := FormatSecretReport(SecretReport{Secrets: secrets, Redact: RedactNone})
+
+	for _, want := range []string{
+		"DETECTOR: TEMPLATE (CRITICAL) (1 findings)",
+		"DETECTOR: TRUFFLEHOG (0 findings)",
+		"DETECTOR: SEMGREP (1 findings)",
+		"DETECTOR: TEMPLATE () (1 findings)",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q", want)
+		}
+	}
+	for _, s := range secrets {
+		if !strings.Contains(report, s.Value) {
+			t.Errorf("finding %q missing from the report", s.Value)
+		}
+	}
+	// Extra sections follow the fixed ones
+	if strings.Index(report, "SEMGREP") < strings.Index(report, "TRUFFLEHOG") {
+		t.Error("extra section printed before the fixed ones")
+	}
+}
@@ -0,0 +1,130 @@
+package scan
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/shaniidev/keyana/internal/core"
+	"github.com/shaniidev/keyana/internal/utils"
+)
+
+// Base confidence of findings that do not come from a template
+var detectorConfidence = map[string]int{
+	"Regex (Entropy)": 45,
+	"Entropy (Pure)":  30,
+	"gitleaks":        70,
+	"jsluice":         60,
+	"trufflehog":      75,
+}
+
+// credentialWords hint that a value nearby is a credential. They count as
+// context hits in addition to a pattern's own context_keywords.
+var credentialWords = []string{"key", "secret", "token", "password", "passwd", "auth", "credential", "bearer", "private"}
+
+// Directories that hold third-party code
+var vendorDirs = map[string]bool{"node_modules": true, "vendor": true, "vendors": true, "bower_components": true}
+
+// vendorBundle matches the base names of third-party bundles: a library name
+// followed only by version, build and hash parts (jquery-3.6.0.min.js,
+// react-dom.production.min.js, chunk-vendors.3f2a9c.js), or a webpack
+// vendors~ chunk. Matches there are mostly library constants and
+// documentation samples rather than the site's own secrets.
+var vendorBundle = regexp.MustCompile(`^(?:(?:jquery|react-dom|lodash|moment|bootstrap|angular|vue(?:\.runtime)?|polyfills?|chunk-vendors|vendors?)` +
+	`(?:[.\-](?:v?\d\w*|min|slim|bundle|esm|cjs|umd|global|prod|production|development|with-locales|[0-9a-f]{6,}))*` +
+	`|vendors~[\w~.\-]*)\.m?js$`)
+
+// contextHits counts the distinct keywords (the pattern's context keywords
+// plus generic credential words) within window bytes of a match
+func contextHits(content []byte, start, end, window int, keys []string) int {
+	if window <= 0 {
+		window = DefaultContextWindow
+	}
+	from := start - window
+	if from < 0 {
+		from = 0
+	}
+	to := end + window
+	if to > len(content) {
+		to = len(content)
+	}
+
+	region := bytes.ToLower(content[from:to])
+	hits := 0
+	seen := make(map[string]bool)
+	for _, list := range [][]string{keys, credentialWords} {
+		for _, kw := range list {
+			if !seen[kw] && bytes.Contains(region, []byte(kw)) {
+				seen[kw] = true
+				hits++
+			}
+		}
+	}
+	return hits
+}
+
+// isVendorFile reports whether the path or URL is a third-party bundle: a
+// file under a vendor directory or named like a library build
+func isVendorFile(path string) bool {
+	p := strings.ToLower(strings.ReplaceAll(path, "\\", "/"))
+	p, _, _ = strings.Cut(p, "?")
+	segments := strings.Split(p, "/")
+	for _, dir := range segments[:len(segments)-1] {
+		if vendorDirs[dir] {
+			return true
+		}
+	}
+	return vendorBundle.MatchString(segments[len(segments)-1])
+}
+
+// ScoreFinding sets sec.Score from its base Confidence, the entropy of the
+// value, the number of context hits around it and the file it was found in.
+// A missing Confidence is taken from the detector defaults.
+func ScoreFinding(sec *core.Secret, hits int) {
+	if sec.Confidence == 0 {
+		sec.Confidence = detectorConfidence[sec.Detector]
+	}
+	score := sec.Confidence
+
+	// Random-looking values are more likely to be live credentials
+	entropy := utils.CalculateEntropy(sec.Value)
+	switch {
+	case entropy < 3.0:
+		score -= 15
+	case entropy < 3.5:
+		score -= 5
+	case entropy >= 4.5:
+		score += 5
+	}
+
+	if hits > 3 {
+		hits = 3
+	}
+	score += 5 * hits
+
+	if isVendorFile(sec.File) {
+		score -= 15
+	}
+
+	if score < 0 {
+		score = 0
+	}
+	if score > 100 {
+		score = 100
+	}
+	sec.Score = score
+}
+
+// filterByScore drops findings scoring below min (min <= 0 keeps everything)
+func filterByScore(found []core.Secret, min int) []core.Secret {
+	if min <= 0 {
+		return found
+	}
+	kept := found[:0]
+	for _, sec := range found {
+		if sec.Score >= min {
+			kept = append(kept, sec)
+		}
+	}
+	return kept
+}
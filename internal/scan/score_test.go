@@ -0,0 +1,169 @@
+package scan
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shaniidev/keyana/internal/core"
+)
+
+func TestScoreFinding(t *testing.T) {
+	const (
+		low    = "passwordpass"                        // Entropy 2.58
+		edge   = "abcdefghabcdefgh"                    // Exactly 3.0
+		medium = "password1234"                        // 3.42
+		normal = "Xk9pQ2mZ7vL4"                        // 3.58
+		high   = "R8vN2qLx5Tz0Kw7Ym3Hb9Fd1Sc6Ja4Pe8Ug" // 5.07
+	)
+	tests := []struct {
+		name string
+		sec  core.Secret
+		hits int
+		want int
+	}{
+		{"low entropy", core.Secret{Confidence: 60, Value: low}, 0, 45},
+		{"entropy 3.0 is medium", core.Secret{Confidence: 60, Value: edge}, 0, 55},
+		{"medium entropy", core.Secret{Confidence: 60, Value: medium}, 0, 55},
+		{"normal entropy", core.Secret{Confidence: 60, Value: normal}, 0, 60},
+		{"high entropy", core.Secret{Confidence: 60, Value: high}, 0, 65},
+		{"one context hit", core.Secret{Confidence: 60, Value: normal}, 1, 65},
+		{"three context hits", core.Secret{Confidence: 60, Value: normal}, 3, 75},
+		{"context hits are capped at three", core.Secret{Confidence: 60, Value: normal}, 7, 75},
+		{"vendor directory", core.Secret{Confidence: 60, Value: normal, File: "out/node_modules/pkg/index.js"}, 0, 45},
+		{"vendor bundle", core.Secret{Confidence: 60, Value: normal, File: `C:\site\js\Chunk-Vendors.3f2a.js`}, 0, 45},
+		{"minified library", core.Secret{Confidence: 60, Value: normal, File: "static/jquery.min.js"}, 0, 45},
+		{"own bundle", core.Secret{Confidence: 60, Value: normal, File: "static/js/main.3f2a.js"}, 0, 60},
+		{"detector default", core.Secret{Detector: "gitleaks", Value: normal}, 0, 70},
+		{"unknown detector", core.Secret{Detector: "other", Value: high}, 0, 5},
+		{"clamped at zero", core.Secret{Detector: "Entropy (Pure)", Value: low, File: "vendor/a.js"}, 0, 0},
+		{"clamped at 100", core.Secret{Confidence: 95, Value: high}, 3, 100},
+	}
+	for _, tt := range tests {
+		sec := tt.sec
+		ScoreFinding(&sec, tt.hits)
+		if sec.Score != tt.want {
+			t.Errorf("%s: score %d, want %d", tt.name, sec.Score, tt.want)
+		}
+	}
+
+	// The detector default becomes the finding's base confidence
+	sec := core.Secret{Detector: "jsluice", Value: normal}
+	ScoreFinding(&sec, 0)
+	if sec.Confidence != 60 {
+		t.Errorf("base confidence %d, want the jsluice default 60", sec.Confidence)
+	}
+}
+
+func TestIsVendorFile(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"out/node_modules/pkg/index.js", true},
+		{"static/vendor/widget.js", true},
+		{`C:\site\bower_components\x\x.js`, true},
+		{"static/jquery.min.js", true},
+		{"static/jquery-3.6.0.min.js", true},
+		{"js/bootstrap.js", true},
+		{"js/bootstrap.bundle.min.js", true},
+		{"js/moment.min.js", true},
+		{"js/moment-with-locales.min.js", true},
+		{"js/react-dom.production.min.js", true},
+		{"js/vue.runtime.esm.js", true},
+		{"js/polyfills.4e1b2c9d.js", true},
+		{"js/chunk-vendors.3f2a9c.js", true},
+		{"js/vendors~main.chunk.js", true},
+		{"js/Lodash.MIN.js", true},
+		{"https://cdn.example.net/jquery.min.js?v=3", true},
+		{"static/js/main.3f2a.js", false},
+		{"js/app-bootstrap.js", false},
+		{"js/bootstrap/main.js", false},
+		{"js/momentum.js", false},
+		{"js/moment-service.js", false},
+		{"js/jquery-plugin-config.js", false},
+		{"js/vendored-config.js", false},
+		{"vendor.example.com/app.js", false},
+	}
+	for _, tt := range tests {
+		if got := isVendorFile(tt.path); got != tt.want {
+			t.Errorf("isVendorFile(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestContextHits(t *testing.T) {
+	pad := func(n int) string { return strings.Repeat(" ", n) }
+	tests := []struct {
+		content string
+		keys    []string
+		want    int
+	}{
+		{`x = "VALUE"`, nil, 0},
+		{`apiKey = "VALUE"`, nil, 1},
+		{`SECRET_KEY = "VALUE"`, nil, 2},
+		{`token = "VALUE"; token2`, nil, 1},             // Each keyword counts once
+		{`vault_token = "VALUE"`, []string{"vault"}, 2}, // Pattern keywords add to the generic ones
+		{`key = "VALUE"`, []string{"key"}, 1},           // A pattern keyword that is also generic
+		{"token" + pad(20) + "VALUE", nil, 0},           // Starts outside the window
+		{"VALUE" + pad(5) + "// password", nil, 1},      // After the match
+		{"VALUE" + pad(15) + "// password", nil, 0},     // Ends past the window
+	}
+	for _, tt := range tests {
+		start := strings.Index(tt.content, "VALUE")
+		got := contextHits([]byte(tt.content), start, start+len("VALUE"), 20, tt.keys)
+		if got != tt.want {
+			t.Errorf("contextHits(%q, %v) = %d, want %d", tt.content, tt.keys, got, tt.want)
+		}
+	}
+}
+
+func TestFilterByScore(t *testing.T) {
+	found := func() []core.Secret {
+		return []core.Secret{{Value: "a", Score: 30}, {Value: "b", Score: 70}, {Value: "c", Score: 50}, {Value: "d", Score: 0}}
+	}
+	tests := []struct {
+		min  int
+		want []string
+	}{
+		{-1, []string{"a", "b", "c", "d"}},
+		{0, []string{"a", "b", "c", "d"}},
+		{1, []string{"a", "b", "c"}},
+		{50, []string{"b", "c"}},
+		{51, []string{"b"}},
+		{100, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, s := range filterByScore(found(), tt.min) {
+			got = append(got, s.Value)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("min %d: kept %v, want %v", tt.min, got, tt.want)
+		}
+	}
+}
+
+// -min-confidence applies to the score, not the pattern's base confidence
+func TestMinConfidence(t *testing.T) {
+	e := NewEngine([]CompiledPattern{
+		mustCompile(t, PatternTemplate{ID: "r8", Regex: `R8vN[A-Za-z0-9]{31}`, Confidence: 50}),
+	})
+	const content = `var v = "R8vN2qLx5Tz0Kw7Ym3Hb9Fd1Sc6Ja4Pe8Ug";`
+	tests := []struct {
+		min  int
+		want bool
+	}{
+		{0, true},
+		{50, true},
+		{55, true}, // Base 50 plus 5 for the entropy
+		{56, false},
+	}
+	for _, tt := range tests {
+		found := e.Scan(context.Background(), []byte(content), "app.js", ScanOptions{MinConfidence: tt.min})
+		if got := len(found) == 1; got != tt.want {
+			t.Errorf("min %d: reported %v, want %v (%+v)", tt.min, got, tt.want, found)
+		}
+	}
+}
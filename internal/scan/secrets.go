@@ -1,12 +1,10 @@
 package scan
 
 import (
-	"bufio"
 	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -15,15 +13,11 @@ import (
 
 	"github.com/shaniidev/keyana/internal/config"
 	"github.com/shaniidev/keyana/internal/core"
+	"github.com/shaniidev/keyana/internal/manifest"
 	"github.com/shaniidev/keyana/internal/ui"
 	"github.com/shaniidev/keyana/internal/utils"
 )
 
-// templatePatternsLoaded tracks if templates were loaded
-var templatePatternsLoaded bool
-var templatePatterns []CompiledPattern
-var templateLoadOnce sync.Once
-
 // ============================================================================
 // PACKAGE-LEVEL COMPILED REGEX (Performance Optimization)
 // ============================================================================
@@ -53,16 +47,21 @@ var falsePositivePatterns = []*regexp.Regexp{
 	// Common test/placeholder values
 	regexp.MustCompile(`(?i)(example|test|demo|sample|placeholder|dummy|fake|mock|lorem|ipsum)`),
 	regexp.MustCompile(`(?i)^(xxx+|___+|\.\.\.+|null|undefined|none|empty)$`),
+}
 
+// Shape-only false positive patterns. They judge a whole token by its
+// alphabet and length, so a credential captured out of a longer match
+// (a base64 AccountKey, a hex auth token) must not be held to them.
+var falsePositiveShapes = []*regexp.Regexp{
 	// Hash/ID patterns that aren't secrets
 	regexp.MustCompile(`^[0-9a-f]{32}$`), // MD5-like (without context)
 	regexp.MustCompile(`^[0-9a-f]{40}$`), // SHA1-like (git commit)
 	regexp.MustCompile(`^[0-9a-f]{64}$`), // SHA256-like
 
 	// Base64/Base58 alphabet definitions (NOT secrets)
-	regexp.MustCompile(`^[A-Za-z0-9+/=]{64,}$`),                                           // Base64 alphabet
-	regexp.MustCompile(`^[123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz]+$`), // Base58 alphabet
-	regexp.MustCompile(`^[A-Z]{52,}$`),                                                    // All caps alphabet (font data etc)
+	regexp.MustCompile(`^[A-Za-z0-9+/=]{64,}$`),                                              // Base64 alphabet
+	regexp.MustCompile(`^[123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz]{58}$`), // Base58 alphabet
+	regexp.MustCompile(`^[A-Z]{52,}$`),                                                       // All caps alphabet (font data etc)
 
 	// Unicode/Font data
 	regexp.MustCompile(`^[A-Za-z0-9/+]{500,}$`), // Long base64 encoded data (fonts, images)
@@ -75,16 +74,82 @@ var stringLiteralRe = regexp.MustCompile(`['"]([a-zA-Z0-9_\-+/=]{32,})['"]`)
 // SCANNER IMPLEMENTATION
 // ============================================================================
 
+// ContentScanner finds secrets in a single blob of content
+type ContentScanner interface {
+	ScanBytes(ctx context.Context, content []byte, meta core.ScanMeta) ([]core.Secret, error)
+}
+
+// EngineScanner is the ContentScanner of an Engine run with fixed options
+type EngineScanner struct {
+	Engine  *Engine
+	Options ScanOptions
+}
+
+// ScanBytes scans content, failing with ctx's error if ctx is done before
+// the scan completes
+func (s EngineScanner) ScanBytes(ctx context.Context, content []byte, meta core.ScanMeta) ([]core.Secret, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	found := s.Engine.Scan(ctx, content, meta.Path, s.Options)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
 type SecretScanner struct {
-	Config *config.Config
+	Config  *config.Config
+	Scanner ContentScanner // Applied to every file in the Keyana stage
+
+	// Ignore suppresses allowlisted findings from every detector.
+	// Suppressed counts them once merged, like the findings Run returns.
+	Ignore       *IgnoreList
+	Suppressed   int
+	suppressed   []core.Secret
+	suppressedMu sync.Mutex
+
+	// Manifest supplies the URL and hash of scanned files, if set
+	Manifest *manifest.Manifest
+
+	// External scanners run after the Keyana stage
+	External []ExternalScanner
 }
 
-func NewSecretScanner(cfg *config.Config) *SecretScanner {
-	return &SecretScanner{Config: cfg}
+func NewSecretScanner(cfg *config.Config, scanner ContentScanner) *SecretScanner {
+	return &SecretScanner{Config: cfg, Scanner: scanner, External: ExternalScanners(cfg)}
 }
 
-func (s *SecretScanner) SetSkipGeneric(skip bool) {
-	s.Config.SkipGeneric = skip
+// suppress drops findings matched by the ignore list, keeping them aside
+// to be counted after merging
+func (s *SecretScanner) suppress(found []core.Secret) []core.Secret {
+	if s.Ignore.Len() == 0 {
+		return found
+	}
+	var kept, dropped []core.Secret
+	for _, sec := range found {
+		if s.Ignore.Match(sec) {
+			dropped = append(dropped, sec)
+		} else {
+			kept = append(kept, sec)
+		}
+	}
+	if len(dropped) > 0 {
+		s.suppressedMu.Lock()
+		s.suppressed = append(s.suppressed, dropped...)
+		s.suppressedMu.Unlock()
+	}
+	return kept
+}
+
+// annotate sets the URL and hash of findings from the file manifest
+func (s *SecretScanner) annotate(found []core.Secret) []core.Secret {
+	for i := range found {
+		if e, ok := s.Manifest.Lookup(found[i].File); ok {
+			found[i].URL, found[i].SHA256 = e.URL, e.SHA256
+		}
+	}
+	return found
 }
 
 func (s *SecretScanner) Run(files []string) []core.Secret {
@@ -113,80 +178,35 @@ func (s *SecretScanner) Run(files []string) []core.Secret {
 	fmt.Fprintf(logFile, "Completed in: %s\n", time.Since(startTime))
 	fmt.Fprintf(logFile, "Findings: %d\n\n", len(res))
 
-	// 2. Gitleaks
-	ui.Info("Starting Gitleaks...")
-	fmt.Fprintf(logFile, "--- GITLEAKS SCANNER ---\n")
-	startTime = time.Now()
-	resGL := s.runGitleaks(logFile)
-	secrets = append(secrets, resGL...)
-	fmt.Fprintf(logFile, "Completed in: %s\n", time.Since(startTime))
-	if len(resGL) == 0 {
-		fmt.Fprintf(logFile, "Status: No findings\n")
+	// 2. External scanners, on a staged view of exactly the scanned files
+	if external := availableScanners(s.External, logFile); len(external) > 0 {
+		stage, err := NewStage(s.Config.OutputDir, files)
+		if err != nil {
+			ui.Warning("Could not stage files for external scanners: %v", err)
+			fmt.Fprintf(logFile, "Staging failed: %v\n\n", err)
+		} else {
+			defer stage.Remove()
+		}
+		for _, ext := range external {
+			res := s.suppress(s.annotate(filterByScore(s.runExternal(ext, stage, logFile), s.Config.MinConfidence)))
+			secrets = append(secrets, res...)
+		}
 	}
-	fmt.Fprintf(logFile, "Findings: %d\n\n", len(resGL))
-	ui.Success("Gitleaks finished")
-
-	// 3. JSLuice
-	ui.Info("Starting JSLuice...")
-	fmt.Fprintf(logFile, "--- JSLUICE SCANNER ---\n")
-	startTime = time.Now()
-	fmt.Fprintf(logFile, "Command: jsluice secrets <file>\n")
-	barJS := ui.NewProgressBar(len(files), "JSLuice")
-
-	var mu sync.Mutex
-	var wg sync.WaitGroup
-	var logMu sync.Mutex
-	sem := make(chan struct{}, s.Config.Concurrency)
-	var jsLuiceResults []core.Secret
-	var jsLuiceErrors int
 
-	for _, f := range files {
-		wg.Add(1)
-		go func(filePath string) {
-			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
-			defer barJS.Increment()
-
-			r, err := s.runJSLuice(filePath, logFile, &logMu)
-			mu.Lock()
-			if err != nil {
-				jsLuiceErrors++
-			} else {
-				jsLuiceResults = append(jsLuiceResults, r...)
-				secrets = append(secrets, r...)
-			}
-			mu.Unlock()
-		}(f)
-	}
-	wg.Wait()
-	fmt.Fprintf(logFile, "Completed in: %s\n", time.Since(startTime))
-	fmt.Fprintf(logFile, "Files scanned: %d\n", len(files))
-	fmt.Fprintf(logFile, "Files with errors: %d\n", jsLuiceErrors)
-	fmt.Fprintf(logFile, "Findings: %d\n", len(jsLuiceResults))
-	if len(jsLuiceResults) == 0 && jsLuiceErrors == 0 {
-		fmt.Fprintf(logFile, "Status: No findings (scanner executed successfully)\n")
-	}
-	fmt.Fprintf(logFile, "\n")
-
-	// 4. TruffleHog
-	ui.Info("Starting TruffleHog...")
-	fmt.Fprintf(logFile, "--- TRUFFLEHOG SCANNER ---\n")
-	startTime = time.Now()
-	truffleHogResults := s.runTruffleHog(logFile)
-	secrets = append(secrets, truffleHogResults...)
-	fmt.Fprintf(logFile, "Completed in: %s\n", time.Since(startTime))
-	fmt.Fprintf(logFile, "Findings: %d\n", len(truffleHogResults))
-	if len(truffleHogResults) == 0 {
-		fmt.Fprintf(logFile, "Status: No findings (scanner executed successfully)\n")
-	}
-	fmt.Fprintf(logFile, "\n")
+	// One finding per leaked value and place, whichever detectors saw it
+	total := len(secrets)
+	secrets = MergeFindings(secrets)
+	// A value several detectors saw and an ignore rule dropped is one suppression
+	s.Suppressed = len(MergeFindings(s.suppressed))
+	fmt.Fprintf(logFile, "--- MERGE ---\n")
+	fmt.Fprintf(logFile, "Findings: %d merged into %d\n\n", total, len(secrets))
 
 	// Summary
 	fmt.Fprintf(logFile, "=================================================================\n")
 	fmt.Fprintf(logFile, "SUMMARY\n")
 	fmt.Fprintf(logFile, "=================================================================\n")
 	fmt.Fprintf(logFile, "Total secrets found: %d\n", len(secrets))
+	fmt.Fprintf(logFile, "Suppressed by ignore rules: %d\n", s.Suppressed)
 	fmt.Fprintf(logFile, "Completed: %s\n", time.Now().Format(time.RFC3339))
 
 	fmt.Printf("[+] Secrets scan log saved: %s\n", logPath)
@@ -200,9 +220,11 @@ func (s *SecretScanner) runRegexScan(files []string, bar *ui.ProgressBar) []core
 		numWorkers = s.Config.Concurrency
 	}
 
-	// Channels for parallel processing
+	// Channels for parallel processing. Every scanned file produces exactly
+	// one batch on resultChan, so the collector below both drives the
+	// progress bar and accumulates findings from a single stream.
 	fileChan := make(chan string, len(files))
-	resultChan := make(chan []core.Secret, len(files))
+	resultChan := make(chan []core.Secret, numWorkers)
 	var wg sync.WaitGroup
 
 	// Start worker pool
@@ -211,8 +233,7 @@ func (s *SecretScanner) runRegexScan(files []string, bar *ui.ProgressBar) []core
 		go func() {
 			defer wg.Done()
 			for filePath := range fileChan {
-				secrets := s.scanSingleFile(filePath)
-				resultChan <- secrets
+				resultChan <- s.scanSingleFile(filePath)
 			}
 		}()
 	}
@@ -225,28 +246,18 @@ func (s *SecretScanner) runRegexScan(files []string, bar *ui.ProgressBar) []core
 		close(fileChan)
 	}()
 
-	// Collect progress
+	// Close the stream once all workers are done
 	go func() {
-		for range resultChan {
-			bar.Increment()
-		}
+		wg.Wait()
+		close(resultChan)
 	}()
 
-	wg.Wait()
-	close(resultChan)
-
-	// Collect all results
+	// Collect results. The same value in other files is kept: MergeFindings
+	// sorts the findings, so the order workers finish in does not matter.
 	var found []core.Secret
-	seenSecrets := make(map[string]bool)
-
-	// Drain any remaining results
 	for secrets := range resultChan {
-		for _, sec := range secrets {
-			if !seenSecrets[sec.Value] {
-				seenSecrets[sec.Value] = true
-				found = append(found, sec)
-			}
-		}
+		bar.Increment()
+		found = append(found, s.suppress(s.annotate(secrets))...)
 	}
 
 	return found
@@ -254,36 +265,66 @@ func (s *SecretScanner) runRegexScan(files []string, bar *ui.ProgressBar) []core
 
 // scanSingleFile processes one file with all optimizations
 func (s *SecretScanner) scanSingleFile(filePath string) []core.Secret {
-	var found []core.Secret
-	seenInFile := make(map[string]bool)
-
 	content, err := os.ReadFile(filePath)
 	if err != nil {
+		return nil
+	}
+
+	found, err := s.Scanner.ScanBytes(context.Background(), content, core.ScanMeta{Path: filePath})
+	if err != nil {
+		return nil
+	}
+	return found
+}
+
+// Scan runs the template patterns and, unless opts.SkipGeneric is set, the
+// generic heuristics over one file's content, then over the decoded form of
+// encoded string literals and over folded string concatenations. It is safe
+// to call on a nil engine, in which case only the heuristics run. Once ctx is
+// done it stops between patterns and returns what it has found so far.
+func (e *Engine) Scan(ctx context.Context, content []byte, filePath string, opts ScanOptions) []core.Secret {
+	found := e.scanPlain(ctx, content, filePath, opts)
+	if opts.DecodeDepth <= 0 && !opts.FoldStrings {
 		return found
 	}
 
+	seen := make(map[string]bool, len(found))
+	for _, sec := range found {
+		seen[sec.Value] = true
+	}
+	if opts.DecodeDepth > 0 && ctx.Err() == nil {
+		found = append(found, e.scanDecoded(ctx, content, filePath, seen, opts)...)
+	}
+	if opts.FoldStrings && ctx.Err() == nil {
+		found = append(found, e.scanFolded(ctx, content, filePath, seen, opts)...)
+	}
+	return found
+}
+
+// scanPlain scans content as is
+func (e *Engine) scanPlain(ctx context.Context, content []byte, filePath string, opts ScanOptions) []core.Secret {
+	var found []core.Secret
+	seenInFile := make(map[string]bool)
+
 	// Pre-calculate line positions once (O(n) → O(log n) for lookups)
 	linePositions := buildLineIndex(content)
 
 	// 1. Template Patterns (High Performance Aho-Corasick Engine)
 	highConfidenceFound := false
-	if templatePatternsLoaded {
-		// Use the O(1) engine scan
-		matches := GlobalEngine.ScanContent(content, filePath, seenInFile, linePositions, s.Config.SkipGeneric)
-
-		for _, m := range matches {
-			found = append(found, m)
-			highConfidenceFound = true
-		}
+	for _, m := range e.ScanContent(ctx, content, filePath, seenInFile, linePositions, opts) {
+		found = append(found, m)
+		highConfidenceFound = true
 	}
 
 	// 2. Skip entropy scanning if high-confidence secrets found
 	if highConfidenceFound {
-		return found
+		return filterByScore(found, opts.MinConfidence)
 	}
 
 	// 3. Generic Heuristic (key=value patterns with entropy) - ONLY if not skipping generic
-	if !s.Config.SkipGeneric {
+	if !opts.SkipGeneric && ctx.Err() == nil {
+		start := time.Now()
+		byValue := make(map[string]int)
 		matches := genericSecretRe.FindAllSubmatchIndex(content, -1)
 		for _, mIdx := range matches {
 			if len(mIdx) < 6 {
@@ -293,6 +334,10 @@ func (s *SecretScanner) scanSingleFile(filePath string) []core.Secret {
 			varName := string(content[mIdx[2]:mIdx[3]])
 			val := string(content[mIdx[4]:mIdx[5]])
 
+			if i, exists := byValue[val]; exists {
+				found[i].AddLocation(newLocation(linePositions, mIdx[4], mIdx[5]))
+				continue
+			}
 			if seenInFile[val] || len(val) < 16 {
 				continue
 			}
@@ -303,23 +348,35 @@ func (s *SecretScanner) scanSingleFile(filePath string) []core.Secret {
 			threshold := getEntropyThreshold(len(val))
 			if utils.CalculateEntropy(val) > threshold {
 				seenInFile[val] = true
-				found = append(found, core.Secret{
+				sec := core.Secret{
 					Type:     fmt.Sprintf("Potential Secret: %s", varName),
 					Value:    val,
+					Context:  string(content[mIdx[0]:mIdx[1]]),
 					File:     filePath,
-					Line:     getLineFromIndex(linePositions, mIdx[0]),
 					Detector: "Regex (Entropy)",
-				})
+					Severity: "low",
+				}
+				sec.AddLocation(newLocation(linePositions, mIdx[4], mIdx[5]))
+				ScoreFinding(&sec, contextHits(content, mIdx[0], mIdx[1], opts.ContextWindow, nil))
+				byValue[val] = len(found)
+				found = append(found, sec)
 			}
 		}
+		opts.Profiler.Record(ProfileGenericAssignment, filePath, time.Since(start), len(content), len(matches))
 	}
 
 	// 4. Pure Entropy Scan - ONLY if not skipping generic
-	if !s.Config.SkipGeneric {
+	if !opts.SkipGeneric {
+		start := time.Now()
+		byValue := make(map[string]int)
 		matchesLit := stringLiteralRe.FindAllSubmatchIndex(content, -1)
 		for _, mIdx := range matchesLit {
 			val := string(content[mIdx[2]:mIdx[3]])
 
+			if i, exists := byValue[val]; exists {
+				found[i].AddLocation(newLocation(linePositions, mIdx[2], mIdx[3]))
+				continue
+			}
 			if seenInFile[val] {
 				continue
 			}
@@ -332,18 +389,23 @@ func (s *SecretScanner) scanSingleFile(filePath string) []core.Secret {
 
 			if utils.CalculateEntropy(val) > 5.2 {
 				seenInFile[val] = true
-				found = append(found, core.Secret{
+				sec := core.Secret{
 					Type:     "High Entropy String",
 					Value:    val,
 					File:     filePath,
-					Line:     getLineFromIndex(linePositions, mIdx[0]),
 					Detector: "Entropy (Pure)",
-				})
+					Severity: "low",
+				}
+				sec.AddLocation(newLocation(linePositions, mIdx[2], mIdx[3]))
+				ScoreFinding(&sec, contextHits(content, mIdx[0], mIdx[1], opts.ContextWindow, nil))
+				byValue[val] = len(found)
+				found = append(found, sec)
 			}
 		}
+		opts.Profiler.Record(ProfileEntropyLiteral, filePath, time.Since(start), len(content), len(matchesLit))
 	}
 
-	return found
+	return filterByScore(found, opts.MinConfidence)
 }
 
 // buildLineIndex creates an index of line start positions for fast lookup
@@ -375,6 +437,17 @@ func getLineFromIndex(positions []int, offset int) int {
 	return lo + 1
 }
 
+// newLocation converts the byte range [start, end) into a Location
+func newLocation(linePositions []int, start, end int) core.Location {
+	line := getLineFromIndex(linePositions, start)
+	return core.Location{
+		Line:      line,
+		Column:    start - linePositions[line-1] + 1,
+		Offset:    start,
+		EndOffset: end,
+	}
+}
+
 // runTemplatePatterns scans content using coregex-powered template patterns (1000+)
 
 // getEntropyThreshold returns adaptive threshold based on string length
@@ -393,6 +466,12 @@ func getEntropyThreshold(length int) float64 {
 
 // isFalsePositive checks if a value matches false positive patterns
 func isFalsePositive(val string) bool {
+	return isFalsePositiveValue(val) || isFalsePositiveShape(val)
+}
+
+// isFalsePositiveValue checks if a value reads as a placeholder, a UI
+// string or an identifier rather than a credential
+func isFalsePositiveValue(val string) bool {
 	lowerVal := strings.ToLower(val)
 
 	// Quick keyword checks
@@ -413,6 +492,45 @@ func isFalsePositive(val string) bool {
 	return false
 }
 
+// maskLiterals blanks out each occurrence of a regex literal in val, so the
+// false positive rules only see the text a pattern lets vary. Literals are
+// matched case-insensitively, like the (?i) patterns they may come from.
+func maskLiterals(val string, literals []string) string {
+	if len(literals) == 0 {
+		return val
+	}
+	b := []byte(val)
+	lower := []byte(strings.ToLower(val))
+	if len(lower) != len(b) {
+		lower = b // Lowercasing changed the byte layout; mask exact text only
+	}
+	for _, lit := range literals {
+		needle := []byte(strings.ToLower(lit))
+		for from := 0; ; {
+			i := bytes.Index(lower[from:], needle)
+			if i < 0 {
+				break
+			}
+			for j := from + i; j < from+i+len(needle); j++ {
+				b[j] = ' '
+			}
+			from += i + len(needle)
+		}
+	}
+	return string(b)
+}
+
+// isFalsePositiveShape checks if a whole token looks like a hash, an
+// alphabet definition or encoded data rather than a credential
+func isFalsePositiveShape(val string) bool {
+	for _, fpRe := range falsePositiveShapes {
+		if fpRe.MatchString(val) {
+			return true
+		}
+	}
+	return false
+}
+
 // isFalsePositiveVar checks if a variable name indicates false positive
 func isFalsePositiveVar(varName string) bool {
 	lowerVar := strings.ToLower(varName)
@@ -465,167 +583,3 @@ func isRepeatingPattern(s string) bool {
 
 	return false
 }
-
-// ============================================================================
-// EXTERNAL SCANNER INTEGRATIONS
-// ============================================================================
-
-type GitleaksResult struct {
-	Description string `json:"Description"`
-	Secret      string `json:"Secret"`
-	File        string `json:"File"`
-	Match       string `json:"Match"`
-	StartLine   int    `json:"StartLine"`
-}
-
-func (s *SecretScanner) runGitleaks(logFile *os.File) []core.Secret {
-	sourceDir := filepath.Join(s.Config.OutputDir, "beautified")
-	tmpReport := filepath.Join(s.Config.OutputDir, "gitleaks_report.json")
-
-	cmdStr := fmt.Sprintf("gitleaks detect --source %s --no-git --report-path %s --exit-code 0", sourceDir, tmpReport)
-	fmt.Fprintf(logFile, "Command: %s\n", cmdStr)
-
-	cmd := exec.Command("gitleaks", "detect", "--source", sourceDir, "--no-git", "--report-path", tmpReport, "--exit-code", "0")
-
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
-	if err != nil {
-		fmt.Fprintf(logFile, "Error executing: %v\n", err)
-		fmt.Fprintf(logFile, "Stderr: %s\n", stderr.String())
-		return []core.Secret{}
-	}
-
-	if stderr.Len() > 0 {
-		fmt.Fprintf(logFile, "Stderr: %s\n", stderr.String())
-	}
-
-	data, err := os.ReadFile(tmpReport)
-	if err != nil {
-		fmt.Fprintf(logFile, "Error reading report: %v\n", err)
-		return []core.Secret{}
-	}
-
-	var glRes []GitleaksResult
-	if err := json.Unmarshal(data, &glRes); err != nil {
-		fmt.Fprintf(logFile, "Error parsing JSON: %v\n", err)
-		return []core.Secret{}
-	}
-
-	var results []core.Secret
-	for _, r := range glRes {
-		results = append(results, core.Secret{
-			Type:     fmt.Sprintf("Gitleaks: %s", r.Description),
-			Value:    r.Secret,
-			File:     r.File,
-			Line:     r.StartLine,
-			Detector: "gitleaks",
-		})
-	}
-
-	os.Remove(tmpReport)
-
-	return results
-}
-
-func (s *SecretScanner) runJSLuice(filePath string, logFile *os.File, logMu *sync.Mutex) ([]core.Secret, error) {
-	cmd := exec.Command("jsluice", "secrets", filePath)
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
-	if err != nil {
-		logMu.Lock()
-		fmt.Fprintf(logFile, "[ERROR] %s - %v\n", filepath.Base(filePath), err)
-		if stderr.Len() > 0 {
-			fmt.Fprintf(logFile, "  Stderr: %s\n", strings.TrimSpace(stderr.String()))
-		}
-		logMu.Unlock()
-		return nil, err
-	}
-
-	var secrets []core.Secret
-	scanner := bufio.NewScanner(&stdout)
-
-	type JSLuiceOut struct {
-		Kind string `json:"kind"`
-		Data string `json:"data"`
-		Line int    `json:"line"`
-	}
-
-	for scanner.Scan() {
-		var jso JSLuiceOut
-		if err := json.Unmarshal(scanner.Bytes(), &jso); err == nil {
-			secrets = append(secrets, core.Secret{
-				Type:     fmt.Sprintf("JSLuice: %s", jso.Kind),
-				Value:    jso.Data,
-				File:     filePath,
-				Line:     jso.Line,
-				Detector: "jsluice",
-			})
-		}
-	}
-
-	return secrets, nil
-}
-
-func (s *SecretScanner) runTruffleHog(logFile *os.File) []core.Secret {
-	beautifiedDir := filepath.Join(s.Config.OutputDir, "beautified")
-
-	cmdStr := fmt.Sprintf("trufflehog filesystem %s --json --no-update --no-verification", beautifiedDir)
-	fmt.Fprintf(logFile, "Command: %s\n", cmdStr)
-
-	cmd := exec.Command("trufflehog", "filesystem", beautifiedDir, "--json", "--no-update", "--no-verification")
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
-	if err != nil {
-		fmt.Fprintf(logFile, "Error executing: %v\n", err)
-		if stderr.Len() > 0 {
-			fmt.Fprintf(logFile, "Stderr: %s\n", stderr.String())
-		}
-		return []core.Secret{}
-	}
-
-	if stderr.Len() > 0 {
-		fmt.Fprintf(logFile, "Stderr: %s\n", stderr.String())
-	}
-
-	var secrets []core.Secret
-	scanner := bufio.NewScanner(&stdout)
-
-	type TruffleHogResult struct {
-		DetectorName   string `json:"DetectorName"`
-		Raw            string `json:"Raw"`
-		SourceMetadata struct {
-			Data struct {
-				Filesystem struct {
-					File string `json:"file"`
-					Line int64  `json:"line"`
-				} `json:"Filesystem"`
-			} `json:"Data"`
-		} `json:"SourceMetadata"`
-		Verified bool `json:"Verified"`
-	}
-
-	for scanner.Scan() {
-		var result TruffleHogResult
-		if err := json.Unmarshal(scanner.Bytes(), &result); err == nil {
-			secrets = append(secrets, core.Secret{
-				Type:     fmt.Sprintf("TruffleHog: %s", result.DetectorName),
-				Value:    result.Raw,
-				File:     result.SourceMetadata.Data.Filesystem.File,
-				Line:     int(result.SourceMetadata.Data.Filesystem.Line),
-				Detector: "trufflehog",
-			})
-		}
-	}
-
-	return secrets
-}
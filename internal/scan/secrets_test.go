@@ -0,0 +1,116 @@
+package scan
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shaniidev/keyana/internal/config"
+	"github.com/shaniidev/keyana/internal/core"
+	"github.com/shaniidev/keyana/internal/manifest"
+)
+
+// newTestScanner returns a SecretScanner over an output directory with one
+// AWS key pattern and no external scanners
+func newTestScanner(t *testing.T, out string) *SecretScanner {
+	t.Helper()
+	e := NewEngine([]CompiledPattern{
+		mustCompile(t, PatternTemplate{ID: "aws", Name: "AWS Access Key", Regex: `AKIA[A-Z0-9]{16}`, Severity: "high"}),
+	})
+	cfg := &config.Config{OutputDir: out, Concurrency: 2}
+	return &SecretScanner{Config: cfg, Scanner: EngineScanner{Engine: e, Options: ScanOptions{SkipGeneric: true}}}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindingsTakeURLFromManifest(t *testing.T) {
+	out := t.TempDir()
+	bundle := filepath.Join(out, "js_files", "app.js")
+	beautified := filepath.Join(out, "js_beautified", "app.js")
+	local := filepath.Join(out, "raw", "local.js")
+	writeTestFile(t, bundle, `var k="AKIAQZ0KX9M2WL4PQRST";`)
+	writeTestFile(t, beautified, "var k = \"AKIAQZ0KX9M2WL4PQRSU\";\n")
+	writeTestFile(t, local, `var k="AKIAQZ0KX9M2WL4PQRSV";`)
+
+	// Record the manifest as a download would, then read it back
+	m, _ := manifest.Load(out)
+	if err := m.Record(manifest.Entry{Path: bundle, URL: "https://example.com/static/app.js"}); err != nil {
+		t.Fatal(err)
+	}
+	m.Derive(beautified, bundle)
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := manifest.Load(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, _ := manifest.HashFile(bundle)
+
+	s := newTestScanner(t, out)
+	s.Manifest = loaded
+
+	byFile := make(map[string]core.Secret)
+	for _, sec := range s.Run([]string{bundle, beautified, local}) {
+		byFile[sec.File] = sec
+	}
+	for _, path := range []string{bundle, beautified} {
+		if sec := byFile[path]; sec.URL != "https://example.com/static/app.js" || sec.SHA256 != sum {
+			t.Errorf("%s: URL %q SHA256 %q, want the bundle's", path, sec.URL, sec.SHA256)
+		}
+	}
+	if sec, ok := byFile[local]; !ok || sec.URL != "" || sec.SHA256 != "" {
+		t.Errorf("file missing from the manifest: found %v, URL %q", ok, sec.URL)
+	}
+}
+
+// staticScanner is an external scanner reporting fixed findings
+type staticScanner struct {
+	name  string
+	found []core.Secret
+}
+
+func (f staticScanner) Name() string                                { return f.name }
+func (f staticScanner) Available() bool                             { return true }
+func (f staticScanner) Run(*Stage, io.Writer) ([]Output, error)     { return []Output{{}}, nil }
+func (f staticScanner) Parse(Output, *Stage) ([]core.Secret, error) { return f.found, nil }
+
+// A value several detectors report is one finding, and one suppression
+func TestSuppressedCountedOncePerFinding(t *testing.T) {
+	out := t.TempDir()
+	file := filepath.Join(out, "js_files", "app.js")
+	writeTestFile(t, file, "var a = \"AKIAQZ0KX9M2WL4PQRST\";\nvar b = \"AKIAQZ0KX9M2WL4PQRSU\";\n")
+
+	external := func(name string) ExternalScanner {
+		return staticScanner{name, []core.Secret{
+			{Detector: name, Type: "AWS", Value: "AKIAQZ0KX9M2WL4PQRST", File: file, Line: 1},
+			{Detector: name, Type: "AWS", Value: "AKIAQZ0KX9M2WL4PQRSU", File: file, Line: 2},
+		}}
+	}
+	var ignore IgnoreList
+	if errs := ignore.Parse(strings.NewReader("value:AKIAQZ0KX9M2WL4PQRST"), "test"); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	s := newTestScanner(t, out)
+	s.Ignore = &ignore
+	s.External = []ExternalScanner{external("gitleaks"), external("trufflehog")}
+	secrets := s.Run([]string{file})
+
+	if len(secrets) != 1 || secrets[0].Value != "AKIAQZ0KX9M2WL4PQRSU" || len(secrets[0].Detectors) != 3 {
+		t.Errorf("findings %+v, want the second key from 3 detectors", secrets)
+	}
+	if s.Suppressed != 1 {
+		t.Errorf("suppressed %d, want 1", s.Suppressed)
+	}
+}
@@ -4,9 +4,10 @@ import (
 	"embed"
 	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
-	"sync"
 
 	"github.com/coregx/coregex"
 	"gopkg.in/yaml.v3"
@@ -15,19 +16,6 @@ import (
 //go:embed patterns
 var embeddedPatterns embed.FS
 
-// SetTemplatePatterns sets the loaded patterns for use by the scanner
-func SetTemplatePatterns(patterns []CompiledPattern) {
-	templatePatterns = patterns
-	templatePatternsLoaded = true
-	// Build the Aho-Corasick engine for rapid scanning
-	BuildEngine(patterns)
-}
-
-// GetPatternCount returns the number of loaded patterns
-func GetPatternCount() int {
-	return len(templatePatterns)
-}
-
 type PatternTemplate struct {
 	ID              string   `yaml:"id"`
 	Name            string   `yaml:"name"`
@@ -41,7 +29,15 @@ type PatternTemplate struct {
 	MaxLength       int      `yaml:"max_length"`
 	Tags            []string `yaml:"tags"`
 	ContextKeywords []string `yaml:"context_keywords"`
+	SecretGroup     string   `yaml:"secret_group"` // Capture group index or name holding the credential
 	References      []string `yaml:"references"`
+	Examples        Examples `yaml:"examples"`
+}
+
+// Examples lists sample inputs a pattern must and must not match
+type Examples struct {
+	Match   []string `yaml:"match"`
+	NoMatch []string `yaml:"no_match"`
 }
 
 type PatternFile struct {
@@ -56,34 +52,54 @@ type PatternFile struct {
 type CompiledPattern struct {
 	ID           string
 	Name         string
-	Regex        *coregex.Regexp
-	RegexString  string // Store original regex for optimization
+	Regex        *coregex.Regexp // Not safe for concurrent use; engines match with their own clones
+	RegexString  string          // Store original regex for optimization
 	Confidence   int
 	Severity     string
 	EntropyCheck bool
 	MinEntropy   float64
+	MinLength    int // Bounds on the extracted secret (0 = unbounded)
+	MaxLength    int
+	ContextKeys  []string // Lowercased context_keywords; one must appear near the match
+	SecretGroup  int      // Capture group holding the credential (0 = the whole match)
+	Literals     []string // Literal text of the regex, kept out of the false positive rules
 	Tags         []string
-	Mutex        *sync.Mutex // Protects Regex from concurrent access (lazy DFA)
+	Category     string // Category and Provider of the pattern file
+	Provider     string
+	Examples     Examples
 }
 
-type PatternLoader struct {
-	patterns []CompiledPattern
-	mu       sync.RWMutex
-	loaded   bool
+// FileReport summarizes the outcome of loading a single pattern file
+type FileReport struct {
+	Path      string
+	Embedded  bool
+	Loaded    int
+	Overrides []string // IDs that replaced an embedded pattern
+	Conflicts []string // IDs already defined by another local file (skipped)
+	Errors    []string
 }
 
-var globalLoader = &PatternLoader{}
+// HasProblems reports whether the file produced conflicts or errors
+func (r FileReport) HasProblems() bool {
+	return len(r.Conflicts) > 0 || len(r.Errors) > 0
+}
 
+// LoadPatterns loads the embedded pattern set
 func LoadPatterns() ([]CompiledPattern, error) {
-	globalLoader.mu.Lock()
-	defer globalLoader.mu.Unlock()
-
-	if globalLoader.loaded {
-		return globalLoader.patterns, nil
-	}
+	patterns, _, err := LoadPatternsWithDirs(nil)
+	return patterns, err
+}
 
+// LoadPatternsWithDirs loads the embedded pattern set and merges in every
+// YAML pattern file found under the given directories. A local pattern
+// replaces an embedded one with the same ID; a local ID defined twice keeps
+// the first definition and is reported as a conflict. Every call compiles a
+// fresh set, so callers own the returned patterns.
+func LoadPatternsWithDirs(dirs []string) ([]CompiledPattern, []FileReport, error) {
 	var allPatterns []CompiledPattern
-	var loadErrors []string
+	var reports []FileReport
+	idIndex := make(map[string]int)       // Pattern ID -> index in allPatterns
+	localOwner := make(map[string]string) // Pattern ID -> local file that defined it
 
 	err := fs.WalkDir(embeddedPatterns, "patterns", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -94,42 +110,119 @@ func LoadPatterns() ([]CompiledPattern, error) {
 			return nil
 		}
 
+		report := FileReport{Path: path, Embedded: true}
 		data, err := embeddedPatterns.ReadFile(path)
 		if err != nil {
-			loadErrors = append(loadErrors, fmt.Sprintf("%s: read error: %v", path, err))
-			return nil
-		}
-
-		var file PatternFile
-		if err := yaml.Unmarshal(data, &file); err != nil {
-			loadErrors = append(loadErrors, fmt.Sprintf("%s: parse error: %v", path, err))
+			report.Errors = append(report.Errors, fmt.Sprintf("read error: %v", err))
+			reports = append(reports, report)
 			return nil
 		}
 
-		for _, pt := range file.Patterns {
-			compiled, err := compilePattern(pt)
-			if err != nil {
-				loadErrors = append(loadErrors, fmt.Sprintf("%s/%s: %v", filepath.Base(path), pt.ID, err))
+		for _, compiled := range parsePatternFile(data, &report) {
+			if _, exists := idIndex[compiled.ID]; exists {
+				report.Conflicts = append(report.Conflicts, compiled.ID)
 				continue
 			}
+			idIndex[compiled.ID] = len(allPatterns)
 			allPatterns = append(allPatterns, compiled)
+			report.Loaded++
 		}
-
+		reports = append(reports, report)
 		return nil
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to walk patterns directory: %w", err)
+		return nil, nil, fmt.Errorf("failed to walk patterns directory: %w", err)
+	}
+
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() || !isPatternFile(path) {
+				return nil
+			}
+
+			report := FileReport{Path: path}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("read error: %v", err))
+				reports = append(reports, report)
+				return nil
+			}
+
+			for _, compiled := range parsePatternFile(data, &report) {
+				if owner, exists := localOwner[compiled.ID]; exists {
+					report.Conflicts = append(report.Conflicts, fmt.Sprintf("%s (already defined in %s)", compiled.ID, owner))
+					continue
+				}
+				localOwner[compiled.ID] = path
+
+				if idx, exists := idIndex[compiled.ID]; exists {
+					allPatterns[idx] = compiled
+					report.Overrides = append(report.Overrides, compiled.ID)
+				} else {
+					idIndex[compiled.ID] = len(allPatterns)
+					allPatterns = append(allPatterns, compiled)
+				}
+				report.Loaded++
+			}
+			reports = append(reports, report)
+			return nil
+		})
+
+		if err != nil {
+			return nil, reports, fmt.Errorf("failed to walk templates directory %s: %w", dir, err)
+		}
+	}
+
+	if len(allPatterns) == 0 {
+		var loadErrors []string
+		for _, r := range reports {
+			for _, e := range r.Errors {
+				loadErrors = append(loadErrors, fmt.Sprintf("%s: %s", r.Path, e))
+			}
+		}
+		if len(loadErrors) > 0 {
+			return nil, reports, fmt.Errorf("no patterns loaded, errors: %v", loadErrors)
+		}
 	}
 
-	globalLoader.patterns = allPatterns
-	globalLoader.loaded = true
+	return allPatterns, reports, nil
+}
+
+// parsePatternFile decodes a PatternFile and compiles its patterns,
+// recording parse and compile errors on the report
+func parsePatternFile(data []byte, report *FileReport) []CompiledPattern {
+	var file PatternFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("parse error: %v", err))
+		return nil
+	}
 
-	if len(loadErrors) > 0 && len(allPatterns) == 0 {
-		return nil, fmt.Errorf("no patterns loaded, errors: %v", loadErrors)
+	var compiled []CompiledPattern
+	for _, pt := range file.Patterns {
+		if pt.ID == "" {
+			report.Errors = append(report.Errors, fmt.Sprintf("pattern %q: missing id", pt.Name))
+			continue
+		}
+		cp, err := compilePattern(pt)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", pt.ID, err))
+			continue
+		}
+		cp.Category = file.Category
+		cp.Provider = file.Provider
+		compiled = append(compiled, cp)
 	}
+	return compiled
+}
 
-	return allPatterns, nil
+func isPatternFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
 }
 
 func compilePattern(pt PatternTemplate) (CompiledPattern, error) {
@@ -138,16 +231,35 @@ func compilePattern(pt PatternTemplate) (CompiledPattern, error) {
 		return CompiledPattern{}, fmt.Errorf("invalid regex: %w", err)
 	}
 
-	severity := pt.Severity
+	severity := strings.ToLower(pt.Severity)
 	if severity == "" {
 		severity = "medium"
 	}
+	if _, ok := severityRank[severity]; !ok {
+		return CompiledPattern{}, fmt.Errorf("unknown severity %q (use low, medium, high or critical)", pt.Severity)
+	}
 
 	confidence := pt.Confidence
 	if confidence == 0 {
 		confidence = 75
 	}
 
+	if pt.MinLength > 0 && pt.MaxLength > 0 && pt.MinLength > pt.MaxLength {
+		return CompiledPattern{}, fmt.Errorf("min_length %d exceeds max_length %d", pt.MinLength, pt.MaxLength)
+	}
+
+	secretGroup, err := resolveSecretGroup(re, pt.SecretGroup)
+	if err != nil {
+		return CompiledPattern{}, err
+	}
+
+	var contextKeys []string
+	for _, kw := range pt.ContextKeywords {
+		if kw = strings.ToLower(strings.TrimSpace(kw)); kw != "" {
+			contextKeys = append(contextKeys, kw)
+		}
+	}
+
 	return CompiledPattern{
 		ID:           pt.ID,
 		Name:         pt.Name,
@@ -157,49 +269,43 @@ func compilePattern(pt PatternTemplate) (CompiledPattern, error) {
 		Severity:     severity,
 		EntropyCheck: pt.EntropyCheck,
 		MinEntropy:   pt.MinEntropy,
+		MinLength:    pt.MinLength,
+		MaxLength:    pt.MaxLength,
+		ContextKeys:  contextKeys,
+		SecretGroup:  secretGroup,
+		Literals:     RegexLiterals(pt.Regex),
 		Tags:         pt.Tags,
-		Mutex:        &sync.Mutex{}, // Initialize mutex
+		Examples:     pt.Examples,
 	}, nil
 }
 
-func GetPatterns() []CompiledPattern {
-	globalLoader.mu.RLock()
-	if globalLoader.loaded {
-		defer globalLoader.mu.RUnlock()
-		return globalLoader.patterns
-	}
-	globalLoader.mu.RUnlock()
+// resolveSecretGroup maps a secret_group value (index or group name) to a
+// capture group index. Without a declaration a group named "secret" is used
+// if present, otherwise the whole match (0).
+func resolveSecretGroup(re *coregex.Regexp, group string) (int, error) {
+	names := re.SubexpNames()
 
-	patterns, err := LoadPatterns()
-	if err != nil {
-		return nil
+	if group == "" {
+		for i, name := range names {
+			if name == "secret" {
+				return i, nil
+			}
+		}
+		return 0, nil
 	}
-	return patterns
-}
 
-func PatternCount() int {
-	return len(GetPatterns())
-}
-
-func GetPatternsByTag(tag string) []CompiledPattern {
-	var result []CompiledPattern
-	for _, p := range GetPatterns() {
-		for _, t := range p.Tags {
-			if strings.EqualFold(t, tag) {
-				result = append(result, p)
-				break
-			}
+	if idx, err := strconv.Atoi(group); err == nil {
+		// Unlike regexp, coregex counts the whole match in NumSubexp
+		if idx < 0 || idx >= re.NumSubexp() {
+			return 0, fmt.Errorf("secret_group %d out of range (regex has %d groups)", idx, re.NumSubexp()-1)
 		}
+		return idx, nil
 	}
-	return result
-}
 
-func GetPatternsBySeverity(severity string) []CompiledPattern {
-	var result []CompiledPattern
-	for _, p := range GetPatterns() {
-		if strings.EqualFold(p.Severity, severity) {
-			result = append(result, p)
+	for i, name := range names {
+		if name == group {
+			return i, nil
 		}
 	}
-	return result
+	return 0, fmt.Errorf("secret_group %q: no such named group", group)
 }
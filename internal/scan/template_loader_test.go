@@ -0,0 +1,196 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSecretGroupRange(t *testing.T) {
+	tests := []struct {
+		group string
+		want  int
+		err   bool
+	}{
+		{"", 0, false},
+		{"0", 0, false},
+		{"1", 1, false},
+		{"2", 0, true}, // One past the only group
+		{"-1", 0, true},
+	}
+	for _, tt := range tests {
+		p, err := compilePattern(PatternTemplate{ID: "t", Regex: `key=([a-z0-9]{12})`, SecretGroup: tt.group})
+		if (err != nil) != tt.err {
+			t.Errorf("secret_group %q: error %v, want error %v", tt.group, err, tt.err)
+			continue
+		}
+		if err == nil && p.SecretGroup != tt.want {
+			t.Errorf("secret_group %q: group %d, want %d", tt.group, p.SecretGroup, tt.want)
+		}
+	}
+
+	_, err := compilePattern(PatternTemplate{ID: "t", Regex: `key=([a-z0-9]{12})`, SecretGroup: "2"})
+	if want := "secret_group 2 out of range (regex has 1 groups)"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("error %v, want %q", err, want)
+	}
+}
+
+func writePatternFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadPatternsWithDirs(t *testing.T) {
+	builtin, err := LoadPatterns()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dirA, dirB := t.TempDir(), t.TempDir()
+	a := filepath.Join(dirA, "a.yaml")
+	dup := filepath.Join(dirA, "z", "dup.yml")
+	b := filepath.Join(dirB, "b.yaml")
+	bad := filepath.Join(dirB, "bad.yaml")
+	writePatternFile(t, a, `
+category: local
+patterns:
+  - id: openai-api-key
+    regex: 'custom_[a-z]{8}'
+  - id: local-a
+    regex: 'local_a_[a-z]{8}'
+`)
+	writePatternFile(t, dup, `
+patterns:
+  - id: local-a
+    regex: 'other_[a-z]{8}'
+`)
+	writePatternFile(t, b, `
+patterns:
+  - id: local-b
+    regex: 'local_b_[a-z]{8}'
+  - id: local-a
+    regex: 'again_[a-z]{8}'
+  - id: broken
+    regex: '(unclosed'
+  - name: No ID
+    regex: 'x'
+`)
+	writePatternFile(t, bad, "patterns: [\n")
+	writePatternFile(t, filepath.Join(dirB, "notes.txt"), "not a pattern file")
+
+	patterns, reports, err := LoadPatternsWithDirs([]string{dirA, dirB})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The override keeps the built-in's place; new IDs are appended
+	if len(patterns) != len(builtin)+2 {
+		t.Errorf("%d patterns, want %d", len(patterns), len(builtin)+2)
+	}
+	byID := make(map[string]CompiledPattern)
+	for i, p := range patterns {
+		byID[p.ID] = p
+		if i < len(builtin) && p.ID != builtin[i].ID {
+			t.Errorf("pattern %d is %s, want %s", i, p.ID, builtin[i].ID)
+		}
+	}
+	if got := byID["openai-api-key"]; got.RegexString != "custom_[a-z]{8}" || got.Category != "local" {
+		t.Errorf("openai-api-key not replaced: regex %q category %q", got.RegexString, got.Category)
+	}
+	if got := byID["local-a"].RegexString; got != "local_a_[a-z]{8}" {
+		t.Errorf("local-a regex %q, want the first definition", got)
+	}
+	if _, ok := byID["broken"]; ok {
+		t.Error("pattern with an invalid regex was loaded")
+	}
+
+	local := make(map[string]FileReport)
+	for _, r := range reports {
+		if !r.Embedded {
+			local[r.Path] = r
+		}
+	}
+	if len(local) != 4 {
+		t.Errorf("%d local file reports, want 4", len(local))
+	}
+
+	if r := local[a]; r.Loaded != 2 || !slices.Equal(r.Overrides, []string{"openai-api-key"}) || r.HasProblems() {
+		t.Errorf("a.yaml: %+v", r)
+	}
+	wantConflict := []string{"local-a (already defined in " + a + ")"}
+	if r := local[dup]; r.Loaded != 0 || !slices.Equal(r.Conflicts, wantConflict) {
+		t.Errorf("z/dup.yml: %+v", r)
+	}
+	if r := local[b]; r.Loaded != 1 || !slices.Equal(r.Conflicts, wantConflict) || len(r.Errors) != 2 {
+		t.Errorf("b.yaml: %+v", r)
+	} else if !strings.HasPrefix(r.Errors[0], "broken: invalid regex") || !strings.Contains(r.Errors[1], "missing id") {
+		t.Errorf("b.yaml errors %q", r.Errors)
+	}
+	if r := local[bad]; len(r.Errors) != 1 || !strings.HasPrefix(r.Errors[0], "parse error") {
+		t.Errorf("bad.yaml: %+v", r)
+	}
+}
+
+func TestPatternSeverity(t *testing.T) {
+	tests := []struct {
+		severity string
+		want     string
+		err      bool
+	}{
+		{"", "medium", false},
+		{"critical", "critical", false},
+		{"Critical", "critical", false},
+		{"HIGH", "high", false},
+		{"info", "", true},
+		{"severe", "", true},
+	}
+	for _, tt := range tests {
+		p, err := compilePattern(PatternTemplate{ID: "t", Regex: `key=[a-z0-9]{12}`, Severity: tt.severity})
+		if (err != nil) != tt.err {
+			t.Errorf("severity %q: error %v, want error %v", tt.severity, err, tt.err)
+			continue
+		}
+		if err == nil && p.Severity != tt.want {
+			t.Errorf("severity %q: got %q, want %q", tt.severity, p.Severity, tt.want)
+		}
+	}
+
+	// An unknown severity is a per-file error, not a silently dropped finding
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sev.yaml")
+	writePatternFile(t, path, `
+patterns:
+  - id: local-upper
+    severity: High
+    regex: 'upper_[a-z]{8}'
+  - id: local-info
+    severity: info
+    regex: 'info_[a-z]{8}'
+`)
+	patterns, reports, err := LoadPatternsWithDirs([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := make(map[string]CompiledPattern)
+	for _, p := range patterns {
+		byID[p.ID] = p
+	}
+	if got := byID["local-upper"].Severity; got != "high" {
+		t.Errorf("local-upper severity %q, want high", got)
+	}
+	if _, ok := byID["local-info"]; ok {
+		t.Error("pattern with an unknown severity was loaded")
+	}
+	for _, r := range reports {
+		if r.Path == path && (len(r.Errors) != 1 || !strings.HasPrefix(r.Errors[0], `local-info: unknown severity "info"`)) {
+			t.Errorf("sev.yaml errors %q", r.Errors)
+		}
+	}
+}
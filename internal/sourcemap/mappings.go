@@ -0,0 +1,123 @@
+package sourcemap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Mapping ties a position in the generated file to one in the source. Lines
+// and columns are 0-based, as in the map format. Only maps with a single
+// source and no names are written and read here.
+type Mapping struct {
+	GenLine, GenCol int
+	SrcLine, SrcCol int
+}
+
+const vlqChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// EncodeMappings returns the "mappings" field for ms, which must be sorted
+// by generated position
+func EncodeMappings(ms []Mapping) string {
+	var sb strings.Builder
+	line, genCol, srcLine, srcCol := 0, 0, 0, 0
+	first := true
+	for _, m := range ms {
+		for line < m.GenLine {
+			sb.WriteByte(';')
+			line++
+			genCol, first = 0, true
+		}
+		if !first {
+			sb.WriteByte(',')
+		}
+		first = false
+		writeVLQ(&sb, m.GenCol-genCol)
+		writeVLQ(&sb, 0) // Source index
+		writeVLQ(&sb, m.SrcLine-srcLine)
+		writeVLQ(&sb, m.SrcCol-srcCol)
+		genCol, srcLine, srcCol = m.GenCol, m.SrcLine, m.SrcCol
+	}
+	return sb.String()
+}
+
+// DecodeMappings parses a "mappings" field. Segments without a source
+// position are skipped.
+func DecodeMappings(s string) ([]Mapping, error) {
+	var ms []Mapping
+	var fields [5]int // genCol, source, srcLine, srcCol, name
+	for line, group := range strings.Split(s, ";") {
+		fields[0] = 0
+		for _, seg := range strings.Split(group, ",") {
+			if seg == "" {
+				continue
+			}
+			n := 0
+			for rest := seg; rest != ""; n++ {
+				if n == len(fields) {
+					return nil, fmt.Errorf("segment %q has too many fields", seg)
+				}
+				v, tail, err := readVLQ(rest)
+				if err != nil {
+					return nil, err
+				}
+				fields[n] += v
+				rest = tail
+			}
+			if n >= 4 {
+				ms = append(ms, Mapping{GenLine: line, GenCol: fields[0], SrcLine: fields[2], SrcCol: fields[3]})
+			}
+		}
+	}
+	return ms, nil
+}
+
+// Lookup returns the mapping at or before a generated position in ms,
+// which must be sorted by generated position
+func Lookup(ms []Mapping, line, col int) (Mapping, bool) {
+	i := sort.Search(len(ms), func(i int) bool {
+		return ms[i].GenLine > line || ms[i].GenLine == line && ms[i].GenCol > col
+	})
+	if i == 0 {
+		return Mapping{}, false
+	}
+	return ms[i-1], true
+}
+
+func writeVLQ(sb *strings.Builder, v int) {
+	u := v << 1
+	if v < 0 {
+		u = -v<<1 | 1
+	}
+	for {
+		digit := u & 31
+		u >>= 5
+		if u > 0 {
+			digit |= 32
+		}
+		sb.WriteByte(vlqChars[digit])
+		if u == 0 {
+			return
+		}
+	}
+}
+
+func readVLQ(s string) (int, string, error) {
+	u, shift := 0, 0
+	for i := 0; i < len(s); i++ {
+		digit := strings.IndexByte(vlqChars, s[i])
+		if digit < 0 {
+			return 0, "", fmt.Errorf("invalid mapping character %q", s[i])
+		}
+		u |= (digit & 31) << shift
+		if digit&32 == 0 {
+			v := u >> 1
+			if u&1 == 1 {
+				v = -v
+			}
+			return v, s[i+1:], nil
+		}
+		shift += 5
+	}
+	return 0, "", fmt.Errorf("truncated mapping segment")
+}
@@ -0,0 +1,88 @@
+package sourcemap
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestVLQ(t *testing.T) {
+	// Known encodings from the source map specification
+	for v, want := range map[int]string{0: "A", 1: "C", -1: "D", 15: "e", 16: "gB", -16: "hB", 1000: "w+B"} {
+		var sb strings.Builder
+		writeVLQ(&sb, v)
+		if sb.String() != want {
+			t.Errorf("encode %d: %q, want %q", v, sb.String(), want)
+		}
+	}
+
+	for _, v := range []int{0, 1, -1, 15, 16, -16, 31, 32, -33, 1000, -1000, 1 << 20, -(1 << 27)} {
+		var sb strings.Builder
+		writeVLQ(&sb, v)
+		got, rest, err := readVLQ(sb.String() + "X")
+		if err != nil || got != v || rest != "X" {
+			t.Errorf("round trip %d: got %d, rest %q, err %v", v, got, rest, err)
+		}
+	}
+}
+
+func TestMappingsRoundTrip(t *testing.T) {
+	ms := []Mapping{
+		{GenLine: 0, GenCol: 0, SrcLine: 0, SrcCol: 0},
+		{GenLine: 0, GenCol: 4, SrcLine: 0, SrcCol: 4},
+		{GenLine: 1, GenCol: 2, SrcLine: 0, SrcCol: 10},
+		{GenLine: 4, GenCol: 0, SrcLine: 0, SrcCol: 3}, // Columns move backwards, lines are skipped
+		{GenLine: 4, GenCol: 35, SrcLine: 2, SrcCol: 1200},
+	}
+	enc := EncodeMappings(ms)
+	if want := "AAAA,IAAI;EAAM;;;AAAP,mCAE6qC"; enc != want {
+		t.Errorf("encoded %q, want %q", enc, want)
+	}
+	got, err := DecodeMappings(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, ms) {
+		t.Errorf("decoded %v, want %v", got, ms)
+	}
+}
+
+func TestDecodeMappings(t *testing.T) {
+	// One-field segments have no source position and are skipped; a name
+	// index is accepted and ignored
+	got, err := DecodeMappings("A,CAAAA;;GACC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Mapping{{GenLine: 0, GenCol: 1}, {GenLine: 2, GenCol: 3, SrcLine: 1, SrcCol: 1}}
+	if !slices.Equal(got, want) {
+		t.Errorf("decoded %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{"AA!A", "AAAg", "AAAAAA"} {
+		if _, err := DecodeMappings(bad); err == nil {
+			t.Errorf("%q: no error", bad)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	ms := []Mapping{{GenLine: 0, GenCol: 4, SrcCol: 10}, {GenLine: 2, GenCol: 0, SrcCol: 20}, {GenLine: 2, GenCol: 8, SrcCol: 30}}
+	tests := []struct {
+		line, col int
+		want      int // SrcCol of the mapping found, -1 = none
+	}{
+		{0, 0, -1},
+		{0, 4, 10},
+		{1, 50, 10},
+		{2, 7, 20},
+		{2, 8, 30},
+		{9, 0, 30},
+	}
+	for _, tt := range tests {
+		m, ok := Lookup(ms, tt.line, tt.col)
+		if !ok && tt.want != -1 || ok && m.SrcCol != tt.want {
+			t.Errorf("Lookup(%d, %d) = %v, %v; want SrcCol %d", tt.line, tt.col, m, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Package sourcemap finds, parses and unpacks JavaScript source maps
+package sourcemap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Map is a version 3 source map. Only the fields needed to recover the
+// original sources and to map positions are decoded.
+type Map struct {
+	Version        int       `json:"version"`
+	File           string    `json:"file,omitempty"`
+	SourceRoot     string    `json:"sourceRoot,omitempty"`
+	Sources        []string  `json:"sources"`
+	SourcesContent []*string `json:"sourcesContent,omitempty"`
+	Names          []string  `json:"names"`
+	Mappings       string    `json:"mappings"`
+}
+
+// Source is one original file unpacked from a map
+type Source struct {
+	Name      string // As listed in the map, e.g. webpack:///./src/api.js
+	LocalPath string
+}
+
+// tailSize is how much of a bundle's end is searched for the map comment
+const tailSize = 4096
+
+// FindURL returns the sourceMappingURL comment of a bundle, resolved
+// against the bundle's URL. It returns "" if the bundle has none. Inline
+// maps are returned as data: URLs.
+func FindURL(content []byte, bundleURL string) string {
+	tail := content
+	if len(tail) > tailSize {
+		tail = tail[len(tail)-tailSize:]
+	}
+
+	var ref string
+	for _, marker := range []string{"//# sourceMappingURL=", "//@ sourceMappingURL="} {
+		if k := bytes.LastIndex(tail, []byte(marker)); k >= 0 {
+			ref = string(tail[k+len(marker):])
+			break
+		}
+	}
+	if ref == "" {
+		// Inline maps are usually larger than the tail
+		if k := bytes.LastIndex(content, []byte("//# sourceMappingURL=data:")); k >= 0 {
+			ref = string(content[k+len("//# sourceMappingURL="):])
+		}
+	}
+	if i := strings.IndexAny(ref, " \t\r\n*"); i >= 0 {
+		ref = ref[:i]
+	}
+	if ref == "" {
+		return ""
+	}
+	if strings.HasPrefix(ref, "data:") || bundleURL == "" {
+		return ref
+	}
+	return Resolve(bundleURL, ref)
+}
+
+// Resolve resolves ref against base. Unparseable input returns ref as is.
+func Resolve(base, ref string) string {
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
+
+// SiblingURL returns the conventional location of a bundle's map: its
+// path with .map appended, query kept (app.js?v=3 -> app.js.map?v=3)
+func SiblingURL(bundleURL string) string {
+	u, err := url.Parse(bundleURL)
+	if err != nil {
+		return bundleURL + ".map"
+	}
+	u.Path += ".map"
+	if u.RawPath != "" {
+		u.RawPath += ".map"
+	}
+	u.Fragment, u.RawFragment = "", ""
+	return u.String()
+}
+
+// DecodeDataURL returns the content of an inline data: map
+func DecodeDataURL(ref string) ([]byte, error) {
+	comma := strings.IndexByte(ref, ',')
+	if !strings.HasPrefix(ref, "data:") || comma < 0 {
+		return nil, fmt.Errorf("not a data URL")
+	}
+	meta, payload := ref[len("data:"):comma], ref[comma+1:]
+	if strings.HasSuffix(meta, ";base64") {
+		return base64.StdEncoding.DecodeString(payload)
+	}
+	s, err := url.PathUnescape(payload)
+	return []byte(s), err
+}
+
+// Parse decodes a source map. Index maps (with "sections") are not supported.
+func Parse(data []byte) (*Map, error) {
+	// Some servers prefix JSON with an XSSI guard
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte(")]}'"))
+	var m Map
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse source map: %w", err)
+	}
+	if m.Version != 3 {
+		return nil, fmt.Errorf("unsupported source map version %d", m.Version)
+	}
+	return &m, nil
+}
+
+// Unpack writes every source with embedded content under dir, keeping the
+// map's directory layout, and returns what was written. Sources without
+// content are skipped.
+func Unpack(m *Map, dir string) ([]Source, error) {
+	var out []Source
+	used := make(map[string]bool)
+	for i, name := range m.Sources {
+		if i >= len(m.SourcesContent) || m.SourcesContent[i] == nil {
+			continue
+		}
+
+		rel := LocalName(m.SourceRoot + name)
+		if rel == "" {
+			rel = fmt.Sprintf("source_%d.js", i)
+		}
+		// Loaders can list the same path twice (e.g. with ?query suffixes)
+		for n := 2; used[rel]; n++ {
+			ext := path.Ext(rel)
+			rel = fmt.Sprintf("%s_%d%s", strings.TrimSuffix(rel, ext), n, ext)
+		}
+		used[rel] = true
+
+		localPath := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+			return out, err
+		}
+		if err := os.WriteFile(localPath, []byte(*m.SourcesContent[i]), 0644); err != nil {
+			return out, err
+		}
+		out = append(out, Source{Name: name, LocalPath: localPath})
+	}
+	return out, nil
+}
+
+// LocalName turns a source name such as webpack:///./src/api.js?abcd into a
+// relative path that cannot escape the unpack directory (webpack/src/api.js)
+func LocalName(name string) string {
+	scheme := ""
+	if i := strings.Index(name, "://"); i >= 0 {
+		scheme, name = name[:i], name[i+3:]
+	}
+	if i := strings.IndexAny(name, "?#"); i >= 0 {
+		name = name[:i]
+	}
+
+	var parts []string
+	if scheme != "" {
+		parts = append(parts, sanitizeSegment(scheme))
+	}
+	for _, seg := range strings.Split(name, "/") {
+		switch seg {
+		case "", ".", "..", "~":
+			continue
+		}
+		parts = append(parts, sanitizeSegment(seg))
+	}
+	return strings.Join(parts, "/")
+}
+
+func sanitizeSegment(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '*', '?', '"', '<', '>', '|', '\\', 0:
+			return '_'
+		}
+		return r
+	}, s)
+}
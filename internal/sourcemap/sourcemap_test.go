@@ -0,0 +1,99 @@
+package sourcemap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSiblingURL(t *testing.T) {
+	tests := map[string]string{
+		"https://cdn.example.com/js/app.js":           "https://cdn.example.com/js/app.js.map",
+		"https://cdn.example.com/js/app.js?v=3":       "https://cdn.example.com/js/app.js.map?v=3",
+		"https://cdn.example.com/js/app.js?v=3&x=a#f": "https://cdn.example.com/js/app.js.map?v=3&x=a",
+		"https://cdn.example.com/js/my%20app.js?v=3":  "https://cdn.example.com/js/my%20app.js.map?v=3",
+	}
+	for bundle, want := range tests {
+		if got := SiblingURL(bundle); got != want {
+			t.Errorf("SiblingURL(%s) = %s, want %s", bundle, got, want)
+		}
+	}
+}
+
+func TestLocalName(t *testing.T) {
+	tests := map[string]string{
+		"webpack:///./src/api.js?abcd":   "webpack/src/api.js",
+		"webpack:///../../../etc/passwd": "webpack/etc/passwd",
+		"../../outside.js":               "outside.js",
+		"/etc/passwd":                    "etc/passwd",
+		"//host/share/x.js":              "host/share/x.js",
+		"~/secrets/.env":                 "secrets/.env",
+		`..\..\win.js`:                   ".._.._win.js",
+		"C:/Windows/x.js":                "C_/Windows/x.js",
+		"./a/./b/../c.js":                "a/b/c.js",
+		"../..":                          "",
+	}
+	for name, want := range tests {
+		if got := LocalName(name); got != want {
+			t.Errorf("LocalName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestUnpackStaysInDir(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "out")
+	content := func(s string) *string { return &s }
+	m := &Map{
+		Version: 3,
+		Sources: []string{
+			"../escape.js",
+			"../../../../../../../../tmp/escape.js",
+			"/etc/escape.js",
+			"webpack:///../escape.js",
+			"src/app.js",
+			"src/app.js?v=2",
+			"..",
+			"no-content.js",
+		},
+		SourcesContent: []*string{
+			content("1"), content("2"), content("3"), content("4"), content("5"), content("6"), content("7"), nil,
+		},
+	}
+
+	sources, err := Unpack(m, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 7 {
+		t.Errorf("%d sources unpacked, want 7", len(sources))
+	}
+	for _, src := range sources {
+		rel, err := filepath.Rel(dir, src.LocalPath)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			t.Errorf("%s written outside the output dir: %s", src.Name, src.LocalPath)
+		}
+	}
+
+	// Nothing but the output dir was created next to it
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "out" {
+		t.Errorf("unexpected entries beside the output dir: %v", entries)
+	}
+
+	// Same local name twice and an empty name get distinct files
+	want := map[string]string{
+		"src/app.js":     "src/app.js",
+		"src/app.js?v=2": "src/app_2.js",
+		"..":             "source_6.js",
+	}
+	for _, src := range sources {
+		if rel, ok := want[src.Name]; ok && src.LocalPath != filepath.Join(dir, filepath.FromSlash(rel)) {
+			t.Errorf("%s unpacked to %s, want %s", src.Name, src.LocalPath, rel)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultRegistrations returns the built-in verifiers and the patterns they
+// check. Only credentials that authenticate an API call are listed; IDs,
+// publishable keys and webhook secrets cannot be verified this way.
+func DefaultRegistrations() []Registration {
+	return []Registration{
+		{
+			Verifier: GitHub{},
+			PatternIDs: []string{
+				"github-pat-classic", "github-pat-fine-grained", "github-oauth-access-token",
+				"github-app-installation-token", "github-user-to-server-token",
+			},
+		},
+		{
+			Verifier: Slack{},
+			PatternIDs: []string{
+				"slack-bot-token", "slack-user-token", "slack-workspace-token",
+				"slack-legacy-token", "slack-connect-token",
+			},
+		},
+		{
+			Verifier: Stripe{},
+			PatternIDs: []string{
+				"stripe-secret-key-live", "stripe-secret-key-test", "stripe-restricted-key",
+			},
+		},
+		{
+			Verifier: OpenAI{},
+			PatternIDs: []string{
+				"openai-api-key", "openai-api-key-legacy", "openai-project-key", "openai-service-account",
+			},
+		},
+	}
+}
+
+// GitHub checks a token against GET /user
+type GitHub struct{}
+
+func (GitHub) Provider() string       { return "github" }
+func (GitHub) DefaultBaseURL() string { return "https://api.github.com" }
+
+func (GitHub) Verify(ctx context.Context, client *http.Client, baseURL, secret string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/user", nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "token "+secret)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	return statusVerdict(client, req, http.StatusOK)
+}
+
+// Slack checks a token with auth.test, which answers 200 either way and
+// reports the outcome in the "ok" field
+type Slack struct{}
+
+func (Slack) Provider() string       { return "slack" }
+func (Slack) DefaultBaseURL() string { return "https://slack.com" }
+
+func (Slack) Verify(ctx context.Context, client *http.Client, baseURL, secret string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/api/auth.test", nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+secret)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return false, fmt.Errorf("decode auth.test response: %w", err)
+	}
+	if body.OK {
+		return true, nil
+	}
+	switch body.Error {
+	case "invalid_auth", "not_authed", "account_inactive", "token_revoked", "token_expired":
+		return false, nil
+	}
+	return false, fmt.Errorf("auth.test: %s", body.Error)
+}
+
+// Stripe checks a secret or restricted key against GET /v1/balance. A
+// restricted key without balance access gets 403, which still proves it live.
+type Stripe struct{}
+
+func (Stripe) Provider() string       { return "stripe" }
+func (Stripe) DefaultBaseURL() string { return "https://api.stripe.com" }
+
+func (Stripe) Verify(ctx context.Context, client *http.Client, baseURL, secret string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/v1/balance", nil)
+	if err != nil {
+		return false, err
+	}
+	req.SetBasicAuth(secret, "")
+	return statusVerdict(client, req, http.StatusOK, http.StatusForbidden)
+}
+
+// OpenAI checks a key against GET /v1/models. 429 means the key is valid
+// but out of quota.
+type OpenAI struct{}
+
+func (OpenAI) Provider() string       { return "openai" }
+func (OpenAI) DefaultBaseURL() string { return "https://api.openai.com" }
+
+func (OpenAI) Verify(ctx context.Context, client *http.Client, baseURL, secret string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/v1/models", nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+secret)
+	return statusVerdict(client, req, http.StatusOK, http.StatusTooManyRequests)
+}
+
+// statusVerdict sends req and maps the status code: any of live means the
+// credential works, 401 means it does not, anything else is an error
+func statusVerdict(client *http.Client, req *http.Request, live ...int) (bool, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	for _, code := range live {
+		if resp.StatusCode == code {
+			return true, nil
+		}
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return false, nil
+	}
+	return false, fmt.Errorf("unexpected status %d", resp.StatusCode)
+}
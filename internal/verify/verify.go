@@ -0,0 +1,246 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaniidev/keyana/internal/core"
+)
+
+// Verifier checks whether a credential is live with a minimal, read-only
+// call to the provider's API
+type Verifier interface {
+	// Provider is the short name used for base URL overrides and rate limits
+	Provider() string
+	// Verify reports whether secret is accepted by the provider. An error
+	// means the answer is unknown (network failure, unexpected status).
+	Verify(ctx context.Context, client *http.Client, baseURL, secret string) (bool, error)
+	// DefaultBaseURL is the production API root
+	DefaultBaseURL() string
+}
+
+// Registration binds a verifier to the findings it can check, by pattern
+// ID or by any tag of the pattern
+type Registration struct {
+	Verifier   Verifier
+	PatternIDs []string
+	Tags       []string
+}
+
+// Options configures a Registry
+type Options struct {
+	BaseURLs    map[string]string // Provider -> API root override (e.g. an httptest server)
+	RatePerSec  float64           // Requests per second per provider (<= 0 = unlimited)
+	Timeout     time.Duration     // Per request
+	Concurrency int
+}
+
+// Registry routes findings to verifiers and enforces per-provider limits
+type Registry struct {
+	opts      Options
+	client    *http.Client
+	byPattern map[string]Verifier
+	limiters  map[string]*rateLimiter
+}
+
+// NewRegistry resolves every registration against the loaded patterns, given
+// as their tags by pattern ID. Tags are expanded to the IDs of the patterns
+// carrying them.
+func NewRegistry(patternTags map[string][]string, regs []Registration, opts Options) *Registry {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	r := &Registry{
+		opts:      opts,
+		client:    &http.Client{Timeout: opts.Timeout},
+		byPattern: make(map[string]Verifier),
+		limiters:  make(map[string]*rateLimiter),
+	}
+
+	for _, reg := range regs {
+		for _, id := range reg.PatternIDs {
+			r.byPattern[id] = reg.Verifier
+		}
+		if len(reg.Tags) > 0 {
+			for _, id := range patternIDsWithTags(patternTags, reg.Tags) {
+				if _, exists := r.byPattern[id]; !exists {
+					r.byPattern[id] = reg.Verifier
+				}
+			}
+		}
+		r.limiters[reg.Verifier.Provider()] = newRateLimiter(opts.RatePerSec)
+	}
+	return r
+}
+
+func patternIDsWithTags(patternTags map[string][]string, tags []string) []string {
+	var ids []string
+	for id, pt := range patternTags {
+		for _, t := range pt {
+			if containsFold(tags, t) {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+func containsFold(list []string, v string) bool {
+	for _, s := range list {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Supports reports whether a verifier is registered for the finding
+func (r *Registry) Supports(sec core.Secret) bool {
+	_, ok := r.byPattern[sec.PatternID]
+	return ok
+}
+
+// Summary counts the outcome of a verification run
+type Summary struct {
+	Checked int
+	Live    int
+	Invalid int
+	Errors  int
+}
+
+// VerifyAll checks every supported finding in place, setting Verified,
+// VerificationError and Verifier. Each distinct provider/value pair is sent
+// to the provider once. Findings an external scanner already verified are
+// left as they are.
+func (r *Registry) VerifyAll(ctx context.Context, secrets []core.Secret) Summary {
+	type outcome struct {
+		live bool
+		err  error
+	}
+
+	var mu sync.Mutex
+	cache := make(map[string]*outcome)
+	pending := make(map[string][]int) // Cache key -> indices of findings
+
+	var keys []string
+	for i, sec := range secrets {
+		v, ok := r.byPattern[sec.PatternID]
+		if !ok || sec.Verified {
+			continue
+		}
+		key := v.Provider() + "\x00" + sec.Value
+		if _, seen := pending[key]; !seen {
+			keys = append(keys, key)
+		}
+		pending[key] = append(pending[key], i)
+	}
+
+	sem := make(chan struct{}, r.opts.Concurrency)
+	var wg sync.WaitGroup
+	for _, key := range keys {
+		first := secrets[pending[key][0]]
+		v := r.byPattern[first.PatternID]
+
+		wg.Add(1)
+		go func(key string, v Verifier, secret string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			live, err := r.verifyOne(ctx, v, secret)
+			mu.Lock()
+			cache[key] = &outcome{live: live, err: err}
+			mu.Unlock()
+		}(key, v, first.Value)
+	}
+	wg.Wait()
+
+	var sum Summary
+	for _, key := range keys {
+		o := cache[key]
+		for _, i := range pending[key] {
+			sec := &secrets[i]
+			sec.Verifier = r.byPattern[sec.PatternID].Provider()
+			sec.Verified = o.live
+			if o.err != nil {
+				sec.VerificationError = o.err.Error()
+			}
+		}
+
+		sum.Checked++
+		switch {
+		case o.err != nil:
+			sum.Errors++
+		case o.live:
+			sum.Live++
+		default:
+			sum.Invalid++
+		}
+	}
+	return sum
+}
+
+func (r *Registry) verifyOne(ctx context.Context, v Verifier, secret string) (bool, error) {
+	if err := r.limiters[v.Provider()].wait(ctx); err != nil {
+		return false, err
+	}
+
+	baseURL := v.DefaultBaseURL()
+	if override, ok := r.opts.BaseURLs[v.Provider()]; ok && override != "" {
+		baseURL = strings.TrimRight(override, "/")
+	}
+
+	live, err := v.Verify(ctx, r.client, baseURL, secret)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", v.Provider(), err)
+	}
+	return live, nil
+}
+
+// rateLimiter spaces requests to one provider evenly
+type rateLimiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+}
+
+func newRateLimiter(perSec float64) *rateLimiter {
+	if perSec <= 0 {
+		return &rateLimiter{}
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / perSec)}
+}
+
+// wait blocks until the next request slot or until ctx is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l.interval == 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(slot))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
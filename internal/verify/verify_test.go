@@ -0,0 +1,159 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shaniidev/keyana/internal/core"
+)
+
+// fakeProvider answers like the provider's API: tokens starting with "live"
+// are accepted, "dead" ones rejected and "limited" ones rate limited
+type fakeProvider struct {
+	path   string
+	secret func(r *http.Request) string
+	live   func(w http.ResponseWriter)
+	dead   func(w http.ResponseWriter)
+}
+
+func (f fakeProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != f.path {
+		http.NotFound(w, r)
+		return
+	}
+	switch secret := f.secret(r); {
+	case strings.HasPrefix(secret, "live"):
+		f.live(w)
+	case strings.HasPrefix(secret, "dead"):
+		f.dead(w)
+	case strings.HasPrefix(secret, "limited"):
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func header(name, prefix string) func(*http.Request) string {
+	return func(r *http.Request) string {
+		return strings.TrimPrefix(r.Header.Get(name), prefix)
+	}
+}
+
+func status(code int) func(http.ResponseWriter) {
+	return func(w http.ResponseWriter) { w.WriteHeader(code) }
+}
+
+func body(s string) func(http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, s)
+	}
+}
+
+func TestVerifiers(t *testing.T) {
+	tests := []struct {
+		verifier    Verifier
+		api         fakeProvider
+		limitedLive bool // Verdict on a rate-limited key
+		limitedErr  bool // Whether a rate limit leaves the answer unknown
+	}{
+		{
+			verifier:   GitHub{},
+			api:        fakeProvider{path: "/user", secret: header("Authorization", "token "), live: status(http.StatusOK), dead: status(http.StatusUnauthorized)},
+			limitedErr: true,
+		},
+		{
+			verifier: Slack{},
+			api: fakeProvider{path: "/api/auth.test", secret: header("Authorization", "Bearer "),
+				live: body(`{"ok":true}`), dead: body(`{"ok":false,"error":"invalid_auth"}`)},
+			limitedErr: true,
+		},
+		{
+			verifier: Stripe{},
+			api: fakeProvider{path: "/v1/balance", secret: func(r *http.Request) string {
+				user, _, _ := r.BasicAuth()
+				return user
+			}, live: status(http.StatusOK), dead: status(http.StatusUnauthorized)},
+			limitedErr: true,
+		},
+		{
+			// 429 is an exhausted quota, which only a valid key can have
+			verifier:    OpenAI{},
+			api:         fakeProvider{path: "/v1/models", secret: header("Authorization", "Bearer "), live: status(http.StatusOK), dead: status(http.StatusUnauthorized)},
+			limitedLive: true,
+		},
+	}
+
+	for _, tt := range tests {
+		provider := tt.verifier.Provider()
+		t.Run(provider, func(t *testing.T) {
+			srv := httptest.NewServer(tt.api)
+			defer srv.Close()
+
+			reg := NewRegistry(nil, []Registration{{Verifier: tt.verifier, PatternIDs: []string{provider}}},
+				Options{BaseURLs: map[string]string{provider: srv.URL + "/"}})
+			secrets := []core.Secret{
+				{PatternID: provider, Value: "live_Kq81xZ"},
+				{PatternID: provider, Value: "dead_Kq81xZ"},
+				{PatternID: provider, Value: "limited_Kq81xZ"},
+			}
+			sum := reg.VerifyAll(context.Background(), secrets)
+
+			want := []struct {
+				verified bool
+				err      bool
+			}{{true, false}, {false, false}, {tt.limitedLive, tt.limitedErr}}
+			for i, w := range want {
+				sec := secrets[i]
+				if sec.Verifier != provider {
+					t.Errorf("%s: verifier %q, want %q", sec.Value, sec.Verifier, provider)
+				}
+				if sec.Verified != w.verified {
+					t.Errorf("%s: verified %v, want %v", sec.Value, sec.Verified, w.verified)
+				}
+				if (sec.VerificationError != "") != w.err {
+					t.Errorf("%s: verification error %q, want error: %v", sec.Value, sec.VerificationError, w.err)
+				}
+			}
+			if sum.Checked != 3 {
+				t.Errorf("checked %d, want 3", sum.Checked)
+			}
+		})
+	}
+}
+
+func TestVerifyAllOncePerValue(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	reg := NewRegistry(nil, DefaultRegistrations(), Options{BaseURLs: map[string]string{"github": srv.URL}, Concurrency: 1})
+	secrets := []core.Secret{
+		{PatternID: "github-pat-classic", Value: "ghp_same"},
+		{PatternID: "github-pat-classic", Value: "ghp_same"},
+		{PatternID: "unsupported", Value: "ghp_same"},
+		{PatternID: "github-pat-classic", Value: "ghp_live", Verifier: "trufflehog", Verified: true},
+	}
+	sum := reg.VerifyAll(context.Background(), secrets)
+
+	if requests != 1 {
+		t.Errorf("%d requests, want 1", requests)
+	}
+	if sum != (Summary{Checked: 1, Invalid: 1}) {
+		t.Errorf("summary %+v", sum)
+	}
+	if secrets[2].Verifier != "" {
+		t.Errorf("unsupported finding was verified by %q", secrets[2].Verifier)
+	}
+	if s := secrets[3]; s.Verifier != "trufflehog" || !s.Verified {
+		t.Errorf("finding verified by trufflehog became %q verified=%v", s.Verifier, s.Verified)
+	}
+}
@@ -0,0 +1,355 @@
+// Package keyana exposes the Keyana secret scanning engine as a library.
+//
+// An Engine is built from a pattern set and holds no global state, so several
+// engines with different patterns can live in one process and a single engine
+// can be shared between goroutines:
+//
+//	patterns, _, err := keyana.LoadPatterns()
+//	if err != nil {
+//		return err
+//	}
+//	engine, err := keyana.NewEngine(patterns, keyana.DefaultOptions())
+//	if err != nil {
+//		return err
+//	}
+//	findings, err := engine.Scan(ctx, resp.Body, keyana.Meta{Path: "app.js"})
+package keyana
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/shaniidev/keyana/internal/core"
+	"github.com/shaniidev/keyana/internal/scan"
+)
+
+// Pattern is a compiled secret detection pattern. Patterns come from
+// LoadPatterns; the zero value cannot be scanned with.
+type Pattern struct {
+	ID         string
+	Name       string
+	Regex      string // Source of the regular expression
+	Severity   string // critical, high, medium or low
+	Confidence int    // Base confidence of its findings (0-100)
+	Tags       []string
+	Category   string // Category and provider of the pattern file
+	Provider   string
+
+	compiled scan.CompiledPattern
+}
+
+func newPattern(p scan.CompiledPattern) Pattern {
+	return Pattern{
+		ID:         p.ID,
+		Name:       p.Name,
+		Regex:      p.RegexString,
+		Severity:   p.Severity,
+		Confidence: p.Confidence,
+		Tags:       p.Tags,
+		Category:   p.Category,
+		Provider:   p.Provider,
+		compiled:   p,
+	}
+}
+
+func compiledPatterns(patterns []Pattern) []scan.CompiledPattern {
+	compiled := make([]scan.CompiledPattern, len(patterns))
+	for i, p := range patterns {
+		compiled[i] = p.compiled
+	}
+	return compiled
+}
+
+// PatternFileReport summarizes the outcome of loading one pattern file
+type PatternFileReport = scan.FileReport
+
+// Filter selects a subset of patterns by tag, severity, category or ID.
+// Empty fields select everything; comparisons ignore case.
+type Filter struct {
+	Tags        []string // Keep patterns carrying any of these tags
+	ExcludeTags []string // Drop patterns carrying any of these tags
+	Severities  []string
+	Categories  []string // Category of the pattern file (cloud, payment, ...)
+	IDs         []string
+}
+
+// IsEmpty reports whether the filter selects every pattern
+func (f Filter) IsEmpty() bool {
+	return scan.PatternFilter(f).IsEmpty()
+}
+
+// Apply returns the patterns that pass the filter
+func (f Filter) Apply(patterns []Pattern) []Pattern {
+	pf := scan.PatternFilter(f)
+	if pf.IsEmpty() {
+		return patterns
+	}
+
+	var result []Pattern
+	for _, p := range patterns {
+		if pf.Match(p.compiled) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// UnknownIDs returns the IDs in the filter that name none of the patterns
+func (f Filter) UnknownIDs(patterns []Pattern) []string {
+	return scan.PatternFilter(f).UnknownIDs(compiledPatterns(patterns))
+}
+
+// ExampleResult is the outcome of checking one pattern against its examples
+type ExampleResult = scan.ExampleResult
+
+// CheckExamples runs every pattern that declares examples against them.
+// A match example passes when the pattern matches it and the keyword
+// prefilter would run the pattern on it; a no_match example passes when the
+// pattern does not match it.
+func CheckExamples(patterns []Pattern) []ExampleResult {
+	compiled := compiledPatterns(patterns)
+	return scan.CheckPatternExamples(scan.NewEngine(compiled), compiled)
+}
+
+// Finding is a secret found in scanned content
+type Finding struct {
+	Type      string
+	Value     string // The credential itself
+	Context   string // Full matched text surrounding the credential (may equal Value)
+	File      string // Meta.Path of the scanned content
+	Line      int
+	Column    int // 1-based byte column of the first occurrence
+	Offset    int // Byte offset of the first occurrence
+	EndOffset int
+	Locations []Location // Every occurrence of Value in the content, in order
+	Detector  string     // "Template" or the heuristic that found it
+	Decoded   string     // How the value was hidden, outermost first (e.g. "base64", "concat")
+
+	PatternID  string // Pattern that produced the finding ("" for heuristics)
+	Severity   string
+	Confidence int // Base confidence of the pattern or heuristic (0-100)
+	Score      int // Confidence adjusted for entropy, context and file (0-100)
+}
+
+// Location is a single occurrence of a finding
+type Location struct {
+	Line      int
+	Column    int
+	Offset    int
+	EndOffset int
+}
+
+func newFinding(sec core.Secret) Finding {
+	f := Finding{
+		Type:       sec.Type,
+		Value:      sec.Value,
+		Context:    sec.Context,
+		File:       sec.File,
+		Line:       sec.Line,
+		Column:     sec.Column,
+		Offset:     sec.Offset,
+		EndOffset:  sec.EndOffset,
+		Detector:   sec.Detector,
+		Decoded:    sec.Decoded,
+		PatternID:  sec.PatternID,
+		Severity:   sec.Severity,
+		Confidence: sec.Confidence,
+		Score:      sec.Score,
+	}
+	for _, l := range sec.Locations {
+		f.Locations = append(f.Locations, Location{Line: l.Line, Column: l.Column, Offset: l.Offset, EndOffset: l.EndOffset})
+	}
+	return f
+}
+
+// secret returns the fields of f that identify and place it
+func (f Finding) secret() core.Secret {
+	return core.Secret{
+		Type:      f.Type,
+		Value:     f.Value,
+		File:      f.File,
+		Line:      f.Line,
+		Column:    f.Column,
+		Detector:  f.Detector,
+		PatternID: f.PatternID,
+	}
+}
+
+// IgnoreList suppresses allowlisted findings; see LoadIgnoreFiles
+type IgnoreList struct {
+	list *scan.IgnoreList
+}
+
+// LoadIgnoreFiles reads .keyanaignore-format files. Missing files are
+// skipped; invalid rules are reported without stopping the load.
+func LoadIgnoreFiles(paths ...string) (*IgnoreList, []error) {
+	list, errs := scan.LoadIgnoreFiles(paths...)
+	return &IgnoreList{list: list}, errs
+}
+
+// Len returns the number of rules
+func (l *IgnoreList) Len() int {
+	return l.list.Len()
+}
+
+// Match reports whether any rule suppresses the finding
+func (l *IgnoreList) Match(f Finding) bool {
+	return l.list.Match(f.secret())
+}
+
+// Filter splits findings into kept ones and the number suppressed
+func (l *IgnoreList) Filter(found []Finding) ([]Finding, int) {
+	var kept []Finding
+	for _, f := range found {
+		if !l.Match(f) {
+			kept = append(kept, f)
+		}
+	}
+	return kept, len(found) - len(kept)
+}
+
+// Fingerprint identifies a finding across runs by pattern ID, normalized
+// value and file name; it is the key used by baselines
+func Fingerprint(f Finding) string {
+	return scan.Fingerprint(f.secret())
+}
+
+// Meta describes the content being scanned
+type Meta = core.ScanMeta
+
+// Stats describes how an engine distributed its patterns over the prefilter
+type Stats = scan.EngineStats
+
+// Profiler records per-pattern timing; see NewProfiler
+type Profiler = scan.PatternProfiler
+
+// PatternStats is the profile of a single pattern
+type PatternStats = scan.PatternStats
+
+// NewProfiler returns a profiler for the given patterns. Runs of a single
+// pattern on a single input slower than budget are flagged (0 disables).
+func NewProfiler(patterns []Pattern, budget time.Duration) *Profiler {
+	return scan.NewPatternProfiler(compiledPatterns(patterns), budget)
+}
+
+// Options controls how an Engine applies its patterns
+type Options struct {
+	SkipGeneric   bool      // Skip keyword-less patterns and the entropy heuristics
+	ContextWindow int       // Bytes around a match searched for context keywords (<= 0 disables the check)
+	Profiler      *Profiler // Records per-pattern timing when set
+	MinConfidence int       // Drop findings whose Score is below this (0-100)
+	DecodeDepth   int       // Encoding layers decoded off string literals (0 disables decoding)
+	FoldStrings   bool      // Also scan constant string concatenations folded into one string
+
+	// OnFinding, if set, is called for every finding as soon as the scan of
+	// its content produces it, before ScanBytes returns. Calls are serialized
+	// across goroutines sharing the engine, so the callback needs no locking.
+	OnFinding func(Finding)
+}
+
+// DefaultOptions returns the options the CLI uses for a DEEP scan
+func DefaultOptions() Options {
+	return Options{
+		ContextWindow: scan.DefaultContextWindow,
+		DecodeDepth:   scan.DefaultDecodeDepth,
+		FoldStrings:   true,
+	}
+}
+
+// LoadPatterns compiles the embedded pattern set plus every YAML pattern file
+// under dirs. Local patterns override embedded ones with the same ID. Files
+// that fail to load are listed in the reports; an error is only returned when
+// no pattern could be loaded at all.
+func LoadPatterns(dirs ...string) ([]Pattern, []PatternFileReport, error) {
+	compiled, reports, err := scan.LoadPatternsWithDirs(dirs)
+	patterns := make([]Pattern, len(compiled))
+	for i, p := range compiled {
+		patterns[i] = newPattern(p)
+	}
+	return patterns, reports, err
+}
+
+// Engine scans content for secrets. It is safe for concurrent use.
+type Engine struct {
+	scanner   scan.EngineScanner
+	patterns  []Pattern
+	onFinding func(Finding)
+	emitMu    sync.Mutex
+}
+
+// NewEngine builds an engine for the given patterns. An empty pattern set is
+// allowed and runs only the generic heuristics (unless SkipGeneric is set).
+func NewEngine(patterns []Pattern, opts Options) (*Engine, error) {
+	for _, p := range patterns {
+		if p.compiled.Regex == nil {
+			return nil, fmt.Errorf("pattern %q was not loaded with LoadPatterns", p.ID)
+		}
+	}
+
+	return &Engine{
+		scanner: scan.EngineScanner{
+			Engine: scan.NewEngine(compiledPatterns(patterns)),
+			Options: scan.ScanOptions{
+				SkipGeneric:   opts.SkipGeneric,
+				ContextWindow: opts.ContextWindow,
+				Profiler:      opts.Profiler,
+				MinConfidence: opts.MinConfidence,
+				DecodeDepth:   opts.DecodeDepth,
+				FoldStrings:   opts.FoldStrings,
+			},
+		},
+		patterns:  patterns,
+		onFinding: opts.OnFinding,
+	}, nil
+}
+
+// Stats reports how the engine's patterns were indexed
+func (e *Engine) Stats() Stats {
+	return e.scanner.Engine.Stats
+}
+
+// Patterns returns the patterns the engine was built from
+func (e *Engine) Patterns() []Pattern {
+	return e.patterns
+}
+
+// Scan reads r to the end and scans its content
+func (e *Engine) Scan(ctx context.Context, r io.Reader, meta Meta) ([]Finding, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read content: %w", err)
+	}
+	return e.ScanBytes(ctx, content, meta)
+}
+
+// ScanBytes scans content and returns its findings. Findings are
+// deduplicated by value within the content. The scan stops between patterns
+// once ctx is done, and then returns ctx's error.
+func (e *Engine) ScanBytes(ctx context.Context, content []byte, meta Meta) ([]Finding, error) {
+	secrets, err := e.scanner.ScanBytes(ctx, content, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]Finding, len(secrets))
+	for i, sec := range secrets {
+		found[i] = newFinding(sec)
+	}
+	e.emit(found)
+	return found, nil
+}
+
+// emit hands findings to the OnFinding hook
+func (e *Engine) emit(found []Finding) {
+	if e.onFinding == nil {
+		return
+	}
+	e.emitMu.Lock()
+	defer e.emitMu.Unlock()
+	for _, f := range found {
+		e.onFinding(f)
+	}
+}